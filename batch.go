@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"example/start/pokeapi"
+	"golang.org/x/sync/errgroup"
+)
+
+const fetchWorkers = 4
+
+type fetchResult struct {
+	name    string
+	pokemon pokeapi.Pokemon
+	err     error
+	elapsed time.Duration
+}
+
+// dedupeNames drops repeated names, keeping the first occurrence of each, and
+// returns the names that were dropped.
+func dedupeNames(names []string) ([]string, []string) {
+	seen := make(map[string]bool, len(names))
+	var unique, dupes []string
+	for _, name := range names {
+		if seen[name] {
+			dupes = append(dupes, name)
+			continue
+		}
+		seen[name] = true
+		unique = append(unique, name)
+	}
+	return unique, dupes
+}
+
+// fetchTimes summarizes how long the fetches in a batch took.
+func fetchTimes(results []fetchResult) (fastest, mean, slowest time.Duration) {
+	if len(results) == 0 {
+		return 0, 0, 0
+	}
+
+	var total time.Duration
+	fastest = results[0].elapsed
+	for _, result := range results {
+		total += result.elapsed
+		if result.elapsed < fastest {
+			fastest = result.elapsed
+		}
+		if result.elapsed > slowest {
+			slowest = result.elapsed
+		}
+	}
+	return fastest, total / time.Duration(len(results)), slowest
+}
+
+// errSkipped replaces the error of a fetch that was cancelled because an
+// earlier one failed with failFast set.
+var errSkipped = errors.New("skipped after an earlier error")
+
+// fetchEach fetches every name concurrently using at most fetchWorkers
+// goroutines, passing each result and its index in names to handle as soon
+// as it is ready. handle may be called from several goroutines at once. Each
+// result carries its own error, so unless failFast is set one failure never
+// stops the rest of the batch. With failFast, the first failure cancels every
+// other fetch. The returned error is the first failure encountered, if any.
+func fetchEach(ctx context.Context, client *pokeapi.Client, names []string, timeout time.Duration, failFast bool, handle func(int, fetchResult)) error {
+	g, groupCtx := errgroup.WithContext(ctx)
+	if !failFast {
+		groupCtx = ctx
+	}
+	g.SetLimit(fetchWorkers)
+	for i, name := range names {
+		i, name := i, name
+		g.Go(func() error {
+			fetchCtx, cancel := context.WithTimeout(groupCtx, timeout)
+			defer cancel()
+
+			emitEvent(progressEvent{Event: "fetch_start", Name: name})
+			start := time.Now()
+			pokemon, err := client.GetPokemon(fetchCtx, name)
+			if err != nil && groupCtx.Err() != nil && ctx.Err() == nil {
+				err = errSkipped
+			}
+			handle(i, fetchResult{name, pokemon, err, time.Since(start)})
+			return err
+		})
+	}
+	return g.Wait()
+}
+
+// fetchAll fetches every name and returns the results in the same order as
+// names.
+func fetchAll(ctx context.Context, client *pokeapi.Client, names []string, timeout time.Duration, failFast bool) ([]fetchResult, error) {
+	results := make([]fetchResult, len(names))
+	err := fetchEach(ctx, client, names, timeout, failFast, func(i int, result fetchResult) {
+		results[i] = result
+	})
+	return results, err
+}
+
+// fetchOrdered fetches every name and sends the results in the same order as
+// names, each as soon as it and every result before it are ready, so work on
+// the first results can start while later ones are still being fetched.
+func fetchOrdered(ctx context.Context, client *pokeapi.Client, names []string, timeout time.Duration, failFast bool) <-chan fetchResult {
+	results := make([]fetchResult, len(names))
+	ready := make([]chan struct{}, len(names))
+	for i := range ready {
+		ready[i] = make(chan struct{})
+	}
+
+	go fetchEach(ctx, client, names, timeout, failFast, func(i int, result fetchResult) {
+		results[i] = result
+		close(ready[i])
+	})
+
+	ordered := make(chan fetchResult)
+	go func() {
+		defer close(ordered)
+		for i := range names {
+			<-ready[i]
+			ordered <- results[i]
+		}
+	}()
+	return ordered
+}
+
+// fetchStream fetches every name and sends the results in the order they
+// complete. The channel is closed once every fetch has finished.
+func fetchStream(ctx context.Context, client *pokeapi.Client, names []string, timeout time.Duration, failFast bool) <-chan fetchResult {
+	results := make(chan fetchResult)
+	go func() {
+		defer close(results)
+		fetchEach(ctx, client, names, timeout, failFast, func(_ int, result fetchResult) {
+			results <- result
+		})
+	}()
+	return results
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"example/start/pokeapi"
+)
+
+const (
+	maxStatValue   = 255
+	chartWidth     = 40
+	statLabelWidth = 16
+	chartBlock     = "█"
+)
+
+// printStatChart draws each stat as a bar scaled against the highest base
+// stat any Pokemon can have, so charts of different Pokemon are comparable.
+func printStatChart(w io.Writer, stats []pokeapi.StatInfo) {
+	for _, info := range stats {
+		n := int(info.BaseStat) * chartWidth / maxStatValue
+		if n == 0 && info.BaseStat > 0 {
+			n = 1
+		}
+		if n > chartWidth {
+			n = chartWidth
+		}
+
+		bar := strings.Repeat(chartBlock, n)
+		fmt.Fprintf(w, "%-*s %-*s %d\n", statLabelWidth, info.Stat.Name, chartWidth, bar, info.BaseStat)
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// printChecksums and writeChecksums are set by -checksum and -checksum-files.
+var (
+	printChecksums bool
+	writeChecksums bool
+)
+
+// recordChecksum reports the SHA-256 of a file that was just saved. Both the
+// log line and the sidecar use the sha256sum format, so a sidecar can be
+// checked with `sha256sum -c` from the file's directory.
+func recordChecksum(filename, sum string) error {
+	if printChecksums {
+		logInfo("%s  %s", sum, filename)
+	}
+	if writeChecksums {
+		line := fmt.Sprintf("%s  %s\n", sum, filepath.Base(filename))
+		if _, err := writeFileAtomic(filename+".sha256", []byte(line)); err != nil {
+			return fmt.Errorf("error saving checksum: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"example/start/pokeapi"
+)
+
+// compactStats are the stats -compact shows, in order, with their labels.
+var compactStats = []struct {
+	name, label string
+}{
+	{"hp", "HP"},
+	{"attack", "ATK"},
+	{"defense", "DEF"},
+	{"speed", "SPD"},
+}
+
+// printCompact writes a Pokemon as a single line such as
+// "#025 pikachu HP:35 ATK:55 DEF:40 SPD:90 BST:320". Stats the Pokemon lacks,
+// for example because -stat filtered them out, are left out of the line.
+func printCompact(w io.Writer, pokemon pokeapi.Pokemon) error {
+	fields := []string{fmt.Sprintf("#%03d", pokemon.Id), pokemon.Name}
+	for _, stat := range compactStats {
+		if v, ok := pokemon.Stat(stat.name); ok {
+			fields = append(fields, fmt.Sprintf("%s:%d", stat.label, v))
+		}
+	}
+	fields = append(fields, fmt.Sprintf("BST:%d", pokemon.TotalStats()))
+
+	_, err := fmt.Fprintln(w, strings.Join(fields, " "))
+	return err
+}
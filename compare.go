@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"example/start/pokeapi"
+)
+
+func higherOf(a, b pokeapi.Pokemon, av, bv int32) string {
+	switch {
+	case av > bv:
+		return a.Name
+	case bv > av:
+		return b.Name
+	default:
+		return "tie"
+	}
+}
+
+// printComparison renders a side-by-side table of two Pokemon with a column
+// naming whichever has the higher value in each row.
+func printComparison(w io.Writer, a, b pokeapi.Pokemon) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "\t%s\t%s\tHigher\n", a.Name, b.Name)
+
+	row := func(label string, av, bv int32) {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\n", label, av, bv, higherOf(a, b, av, bv))
+	}
+	row("height", a.Height, b.Height)
+	row("base experience", a.BaseExp, b.BaseExp)
+	for _, name := range statColumns([]pokeapi.Pokemon{a, b}) {
+		av, _ := a.Stat(name)
+		bv, _ := b.Stat(name)
+		row(name, av, bv)
+	}
+	row("total", a.TotalStats(), b.TotalStats())
+
+	return tw.Flush()
+}
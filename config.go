@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultConfigFile is read from the working directory when -config is not
+// given. Being YAML, it may just as well contain JSON.
+const defaultConfigFile = "gopoke.yaml"
+
+// applyConfig sets every flag named in the config file that was not given on
+// the command line, so explicit flags beat the file and the file beats the
+// built-in defaults. A missing default config file is not an error.
+func applyConfig(filename string) error {
+	explicit := filename != ""
+	if !explicit {
+		filename = defaultConfigFile
+	}
+
+	data, err := os.ReadFile(filename)
+	if os.IsNotExist(err) && !explicit {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading config file: %v", err)
+	}
+
+	var values map[string]interface{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("error parsing config file %s: %v", filename, err)
+	}
+
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	for name, value := range values {
+		if name == "config" || flag.Lookup(name) == nil {
+			return fmt.Errorf("config file %s: unknown setting %q", filename, name)
+		}
+		if set[name] {
+			continue
+		}
+
+		// Lists are accepted for repeatable flags such as stat and header,
+		// setting the flag once per item.
+		var items []string
+		switch v := value.(type) {
+		case nil:
+			return fmt.Errorf("config file %s: %s has no value", filename, name)
+		case []interface{}:
+			for _, item := range v {
+				items = append(items, fmt.Sprint(item))
+			}
+		default:
+			items = []string{fmt.Sprint(v)}
+		}
+
+		for _, s := range items {
+			if err := flag.Set(name, s); err != nil {
+				return fmt.Errorf("config file %s: invalid value %q for %s: %v", filename, s, name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// explicitFlag reports whether the named flag was given on the command line
+// or in the config file.
+func explicitFlag(name string) bool {
+	found := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			found = true
+		}
+	})
+	return found
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// runCounts tallies a run for -count. It is safe for concurrent use.
+type runCounts struct {
+	mu           sync.Mutex
+	start        time.Time
+	fetched      int
+	failed       int
+	spritesSaved int
+	bytes        int64
+}
+
+func newRunCounts() *runCounts {
+	return &runCounts{start: time.Now()}
+}
+
+func (c *runCounts) fetch(ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if ok {
+		c.fetched++
+	} else {
+		c.failed++
+	}
+}
+
+func (c *runCounts) saved(size int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.spritesSaved++
+	c.bytes += int64(size)
+}
+
+// print writes the counts as one key=value line, or as a JSON object when
+// asJSON is set. bytes counts what was written to disk for saved files.
+func (c *runCounts) print(w io.Writer, asJSON bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elapsed := time.Since(c.start)
+
+	if asJSON {
+		data, err := json.Marshal(struct {
+			Fetched        int     `json:"fetched"`
+			Failed         int     `json:"failed"`
+			SpritesSaved   int     `json:"sprites_saved"`
+			Bytes          int64   `json:"bytes"`
+			ElapsedSeconds float64 `json:"elapsed_seconds"`
+		}{c.fetched, c.failed, c.spritesSaved, c.bytes, elapsed.Seconds()})
+		if err != nil {
+			return fmt.Errorf("error encoding JSON: %v", err)
+		}
+		_, err = fmt.Fprintf(w, "%s\n", data)
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "fetched=%d failed=%d sprites_saved=%d bytes=%d elapsed=%v\n",
+		c.fetched, c.failed, c.spritesSaved, c.bytes, elapsed.Round(100*time.Millisecond))
+	return err
+}
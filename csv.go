@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"example/start/pokeapi"
+)
+
+// canonicalStats is the column order used for the stats PokeAPI reports for
+// every Pokemon. Any other stat names found are appended alphabetically.
+var canonicalStats = []string{"hp", "attack", "defense", "special-attack", "special-defense", "speed"}
+
+func statColumns(pokemon []pokeapi.Pokemon) []string {
+	seen := make(map[string]bool)
+	for _, p := range pokemon {
+		for _, info := range p.StatInfo {
+			seen[info.Stat.Name] = true
+		}
+	}
+
+	var columns []string
+	for _, name := range canonicalStats {
+		if seen[name] {
+			columns = append(columns, name)
+			delete(seen, name)
+		}
+	}
+
+	var extra []string
+	for name := range seen {
+		extra = append(extra, name)
+	}
+	sort.Strings(extra)
+
+	return append(columns, extra...)
+}
+
+// writeStatsCSV replaces filename only once the whole table has been
+// written, so an error part way through never leaves a truncated file.
+func writeStatsCSV(filename string, pokemon []pokeapi.Pokemon) error {
+	var buf bytes.Buffer
+	columns := statColumns(pokemon)
+	w := csv.NewWriter(&buf)
+	if err := w.Write(append([]string{"name", "id"}, columns...)); err != nil {
+		return fmt.Errorf("error writing CSV header: %v", err)
+	}
+
+	for _, p := range pokemon {
+		row := []string{p.Name, strconv.Itoa(int(p.Id))}
+		for _, name := range columns {
+			value, ok := p.Stat(name)
+			if !ok {
+				row = append(row, "")
+				continue
+			}
+			row = append(row, strconv.Itoa(int(value)))
+		}
+
+		if err := w.Write(row); err != nil {
+			return fmt.Errorf("error writing CSV row for %s: %v", p.Name, err)
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("error writing CSV file: %v", err)
+	}
+
+	if _, err := writeFileAtomic(filename, buf.Bytes()); err != nil {
+		return fmt.Errorf("error saving CSV file: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"path"
+	"strings"
+
+	"example/start/pokeapi"
+)
+
+func dataURI(spriteURL string, data []byte) string {
+	mediaType := http.DetectContentType(data)
+	if strings.EqualFold(path.Ext(spriteURL), ".svg") {
+		mediaType = "image/svg+xml"
+	}
+	return "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(data)
+}
+
+// embedSprites downloads the sprite for every job and attaches it to the
+// Pokemon it belongs to in output, returning the number of sprites that
+// failed. Invalid sprites from a lenient pool are handled according to the
+// -on-decode-error policy.
+func embedSprites(ctx context.Context, pool *downloadPool, output []outputPokemon, jobs []downloadJob, onDecodeError string) int {
+	byName := make(map[string]*outputPokemon, len(output))
+	for i := range output {
+		output[i].SpriteData = make(map[string]string)
+		byName[output[i].Name] = &output[i]
+	}
+
+	sprites := spriteJobsOnly(jobs)
+
+	failed := 0
+	for result := range pool.Run(ctx, sprites) {
+		job := result.job
+		if result.err != nil {
+			logError("Error downloading %s: %v", job, result.err)
+			failed++
+			continue
+		}
+		if pool.lenient {
+			if err := pokeapi.ValidateSprite(job.url, result.data); err != nil {
+				switch onDecodeError {
+				case "raw":
+					logInfo("Warning: %s: %v; embedding it as downloaded", job, err)
+				case "skip":
+					logInfo("Skipping %s: %v", job, err)
+					continue
+				default:
+					logError("Error: %s: %v", job, err)
+					failed++
+					continue
+				}
+			}
+		}
+		if p, ok := byName[job.pokemon]; ok {
+			p.SpriteData[job.label] = dataURI(job.url, result.data)
+		}
+	}
+	return failed
+}
+
+// spriteJobsOnly returns the jobs in jobs that download images, leaving out
+// raw files such as cries.
+func spriteJobsOnly(jobs []downloadJob) []downloadJob {
+	var sprites []downloadJob
+	for _, job := range jobs {
+		if !job.raw {
+			sprites = append(sprites, job)
+		}
+	}
+	return sprites
+}
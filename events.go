@@ -0,0 +1,39 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// progressEvent is one line of -progress-json output. Fields that don't
+// apply to an event are left out.
+type progressEvent struct {
+	Event  string `json:"event"`
+	Name   string `json:"name,omitempty"`
+	Sprite string `json:"sprite,omitempty"`
+	File   string `json:"file,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// eventOut receives progress events when -progress-json is set. Events are
+// emitted from several goroutines, so writes are serialized.
+var (
+	eventOut io.Writer
+	eventMu  sync.Mutex
+)
+
+func emitEvent(e progressEvent) {
+	if eventOut == nil {
+		return
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+
+	eventMu.Lock()
+	defer eventMu.Unlock()
+	eventOut.Write(append(data, '\n'))
+}
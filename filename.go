@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"example/start/pokeapi"
+)
+
+// fileNameData is what a -filename-template is executed with. Ext includes
+// the leading dot.
+type fileNameData struct {
+	Name    string
+	Id      int32
+	Label   string
+	Side    string
+	Variant string
+	Ext     string
+}
+
+// sanitizeFilename makes s safe to use as a single path element: runs of
+// whitespace and any character that is a separator, reserved on Windows or
+// a control character become one hyphen, and leading dots and hyphens are
+// dropped so the result is never "..", hidden or empty.
+func sanitizeFilename(s string) string {
+	var b strings.Builder
+	pending := false
+	for _, r := range strings.TrimSpace(s) {
+		if unicode.IsSpace(r) || unicode.IsControl(r) || strings.ContainsRune(`/\:*?"<>|`, r) {
+			pending = true
+			continue
+		}
+		if pending && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		pending = false
+		b.WriteRune(r)
+	}
+
+	name := strings.TrimLeft(b.String(), ".-")
+	if name == "" {
+		return "_"
+	}
+	return name
+}
+
+// sanitizeExt applies sanitizeFilename to the part of an extension after its
+// dot.
+func sanitizeExt(ext string) string {
+	if ext == "" {
+		return ""
+	}
+	return "." + sanitizeFilename(strings.TrimPrefix(ext, "."))
+}
+
+// newFileNameData splits a sprite label such as "front_shiny" or
+// "versions_generation-i_red-blue_back_default" into its side, front or
+// back, and the variant left once the side is taken out. Labels without a
+// side, such as "cry", keep the whole label as the variant.
+func newFileNameData(name string, id int32, label, ext string) fileNameData {
+	name, label, ext = sanitizeFilename(name), sanitizeFilename(label), sanitizeExt(ext)
+	data := fileNameData{Name: name, Id: id, Label: label, Ext: ext}
+
+	var rest []string
+	for _, part := range strings.Split(label, "_") {
+		if data.Side == "" && (part == "front" || part == "back") {
+			data.Side = part
+			continue
+		}
+		rest = append(rest, part)
+	}
+	data.Variant = strings.Join(rest, "_")
+	switch {
+	case label == "artwork":
+		data.Side = "front"
+	case data.Variant == "":
+		data.Variant = "default"
+	}
+	return data
+}
+
+// parseFileNameTemplate parses a -filename-template and checks it by
+// executing it against a sample sprite, so a bad field name is reported
+// before anything is fetched.
+func parseFileNameTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("filename").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := renderFileName(tmpl, newFileNameData("pikachu", 25, "front_shiny", ".png")); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// renderFileName executes tmpl and cleans the result into a relative path,
+// which may name subdirectories but can't be absolute or climb out of the
+// output directory.
+func renderFileName(tmpl *template.Template, data fileNameData) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	name := filepath.Clean(filepath.FromSlash(strings.TrimSpace(buf.String())))
+	switch {
+	case name == "." || strings.HasSuffix(buf.String(), "/"):
+		return "", fmt.Errorf("template gives no file name for %s %s", data.Name, data.Label)
+	case filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)):
+		return "", fmt.Errorf("template gives %q for %s %s, which is outside the output directory", name, data.Name, data.Label)
+	}
+	return name, nil
+}
+
+// jobFileName picks where a download is saved: by opts.template when given,
+// else <name>_<label><ext>, or <name>/<label><ext> with -subdirs. The name
+// and label come from the API, so both are sanitized first.
+func jobFileName(outputDir string, pokemon pokeapi.Pokemon, label, ext string, opts spriteOptions) (string, error) {
+	if opts.template != nil {
+		name, err := renderFileName(opts.template, newFileNameData(pokemon.Name, pokemon.Id, label, ext))
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(outputDir, name), nil
+	}
+	name, label, ext := sanitizeFilename(pokemon.Name), sanitizeFilename(label), sanitizeExt(ext)
+	if opts.subdirs {
+		return filepath.Join(outputDir, name, label+ext), nil
+	}
+	return filepath.Join(outputDir, fmt.Sprintf("%s_%s%s", name, label, ext)), nil
+}
+
+// checkFileNames reports an error if two jobs would be saved under the same
+// name, as a -filename-template that leaves out the side or variant does.
+func checkFileNames(jobs []downloadJob) error {
+	seen := make(map[string]downloadJob, len(jobs))
+	for _, job := range jobs {
+		if first, ok := seen[job.filename]; ok {
+			return fmt.Errorf("%s and %s would both be saved as %s", first, job, job.filename)
+		}
+		seen[job.filename] = job
+	}
+	return nil
+}
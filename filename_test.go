@@ -0,0 +1,71 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestSanitizeFilename(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"pikachu", "pikachu"},
+		{"mr-mime", "mr-mime"},
+		{"..", "_"},
+		{".", "_"},
+		{"../../etc/passwd", "etc-passwd"},
+		{"/abs", "abs"},
+		{"/", "_"},
+		{`a\b`, "a-b"},
+		{".hidden", "hidden"},
+		{"...-x", "x"},
+		{"mr   mime", "mr-mime"},
+		{" \t tapu \n koko ", "tapu-koko"},
+		{"a\x00b\x1fc\x7f", "a-b-c"},
+		{`con:*?"<>|x`, "con-x"},
+		{"", "_"},
+		{"   ", "_"},
+	}
+	for _, tt := range tests {
+		got := sanitizeFilename(tt.in)
+		if got != tt.want {
+			t.Errorf("sanitizeFilename(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+		if strings.ContainsAny(got, `/\`) || got == ".." || filepath.IsAbs(got) {
+			t.Errorf("sanitizeFilename(%q) = %q, which is not a single safe path element", tt.in, got)
+		}
+	}
+}
+
+func TestRenderFileName(t *testing.T) {
+	data := newFileNameData("../../etc", 25, "front_shiny", ".png")
+	tests := []struct {
+		template string
+		want     string // empty when the template must be rejected
+	}{
+		{"{{.Name}}_{{.Label}}{{.Ext}}", "etc_front_shiny.png"},
+		{"{{.Id}}/{{.Side}}_{{.Variant}}{{.Ext}}", filepath.Join("25", "front_shiny.png")},
+		{"a/../{{.Name}}{{.Ext}}", "etc.png"},
+		{"../{{.Name}}{{.Ext}}", ""},
+		{"sprites/../../{{.Name}}{{.Ext}}", ""},
+		{"/tmp/{{.Name}}{{.Ext}}", ""},
+		{"..", ""},
+		{"{{.Name}}/", ""},
+		{"", ""},
+		{"  ", ""},
+	}
+	for _, tt := range tests {
+		tmpl := template.Must(template.New("filename").Option("missingkey=error").Parse(tt.template))
+		got, err := renderFileName(tmpl, data)
+		switch {
+		case tt.want == "" && err == nil:
+			t.Errorf("renderFileName(%q) = %q, want an error", tt.template, got)
+		case tt.want != "" && err != nil:
+			t.Errorf("renderFileName(%q): %v", tt.template, err)
+		case got != tt.want:
+			t.Errorf("renderFileName(%q) = %q, want %q", tt.template, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"example/start/pokeapi"
+
+	"gopkg.in/yaml.v3"
+)
+
+// flatStats holds each canonical stat as its own field for -flatten-stats.
+// Keys are the PokeAPI stat names with hyphens turned into underscores; a
+// stat the Pokemon doesn't have is left out.
+type flatStats struct {
+	HP             *int32 `json:"hp,omitempty" yaml:"hp,omitempty"`
+	Attack         *int32 `json:"attack,omitempty" yaml:"attack,omitempty"`
+	Defense        *int32 `json:"defense,omitempty" yaml:"defense,omitempty"`
+	SpecialAttack  *int32 `json:"special_attack,omitempty" yaml:"special_attack,omitempty"`
+	SpecialDefense *int32 `json:"special_defense,omitempty" yaml:"special_defense,omitempty"`
+	Speed          *int32 `json:"speed,omitempty" yaml:"speed,omitempty"`
+}
+
+// flatPokemon is a Pokemon as written, followed by its stats as top-level
+// fields. Stats shadows the embedded stats array so -flatten-only can leave
+// it out by setting it to nil.
+type flatPokemon struct {
+	outputPokemon `yaml:",inline"`
+	flatStats     `yaml:",inline"`
+	Stats         *[]pokeapi.StatInfo `json:"stats,omitempty" yaml:"-"`
+}
+
+// MarshalYAML drops the stats array when Stats is nil. YAML has no field
+// shadowing, so the key is removed from the encoded mapping instead.
+func (f flatPokemon) MarshalYAML() (interface{}, error) {
+	type plain flatPokemon
+	var node yaml.Node
+	if err := node.Encode(plain(f)); err != nil {
+		return nil, err
+	}
+	if f.Stats == nil {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == "stats" {
+				node.Content = append(node.Content[:i], node.Content[i+2:]...)
+				break
+			}
+		}
+	}
+	return &node, nil
+}
+
+func statField(p pokeapi.Pokemon, name string) *int32 {
+	if v, ok := p.Stat(name); ok {
+		return &v
+	}
+	return nil
+}
+
+// flattenPokemon adds the top-level stat fields to p, dropping the stats
+// array as well when only is set.
+func flattenPokemon(p outputPokemon, only bool) flatPokemon {
+	flat := flatPokemon{
+		outputPokemon: p,
+		flatStats: flatStats{
+			HP:             statField(p.Pokemon, "hp"),
+			Attack:         statField(p.Pokemon, "attack"),
+			Defense:        statField(p.Pokemon, "defense"),
+			SpecialAttack:  statField(p.Pokemon, "special-attack"),
+			SpecialDefense: statField(p.Pokemon, "special-defense"),
+			Speed:          statField(p.Pokemon, "speed"),
+		},
+	}
+	if !only {
+		flat.Stats = &flat.StatInfo
+	}
+	return flat
+}
+
+// flattenAll flattens every Pokemon in a batch.
+func flattenAll(pokemon []outputPokemon, only bool) []flatPokemon {
+	flat := make([]flatPokemon, len(pokemon))
+	for i, p := range pokemon {
+		flat[i] = flattenPokemon(p, only)
+	}
+	return flat
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"example/start/pokeapi"
+
+	"gopkg.in/yaml.v3"
+)
+
+func flattenFixture() outputPokemon {
+	return newOutputPokemon(pokeapi.Pokemon{
+		Name: "pikachu",
+		Id:   25,
+		StatInfo: []pokeapi.StatInfo{
+			{Stat: pokeapi.Stat{Name: "hp"}, BaseStat: 35},
+			{Stat: pokeapi.Stat{Name: "speed"}, BaseStat: 90},
+		},
+	})
+}
+
+func TestFlattenPokemon(t *testing.T) {
+	tests := []struct {
+		only      bool
+		wantStats bool
+	}{
+		{only: false, wantStats: true},
+		{only: true, wantStats: false},
+	}
+	for _, tt := range tests {
+		flat := flattenPokemon(flattenFixture(), tt.only)
+
+		data, err := json.Marshal(flat)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var fields map[string]interface{}
+		if err := json.Unmarshal(data, &fields); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := fields["stats"]; ok != tt.wantStats {
+			t.Errorf("only=%v: JSON has stats %v, want %v", tt.only, ok, tt.wantStats)
+		}
+		if fields["hp"] != 35.0 || fields["speed"] != 90.0 {
+			t.Errorf("only=%v: JSON hp, speed = %v, %v, want 35, 90", tt.only, fields["hp"], fields["speed"])
+		}
+		if _, ok := fields["attack"]; ok {
+			t.Errorf("only=%v: JSON has attack the Pokemon lacks", tt.only)
+		}
+
+		out, err := yaml.Marshal(flat)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Contains(string(out), "\nstats:"); got != tt.wantStats {
+			t.Errorf("only=%v: YAML has stats %v, want %v:\n%s", tt.only, got, tt.wantStats, out)
+		}
+		if !strings.Contains(string(out), "\nhp: 35\n") {
+			t.Errorf("only=%v: YAML lacks hp: 35:\n%s", tt.only, out)
+		}
+	}
+}
+
+// Without flattening, a Pokemon whose stats were all filtered out keeps its
+// stats key.
+func TestOutputKeepsEmptyStats(t *testing.T) {
+	p := flattenFixture()
+	p.StatInfo = nil
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"stats":null`) {
+		t.Errorf("JSON = %s, want a stats key", data)
+	}
+}
@@ -1,3 +1,10 @@
 module example/start
 
 go 1.13
+
+require (
+	golang.org/x/image v0.5.0
+	golang.org/x/sync v0.1.0
+	golang.org/x/time v0.3.0
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// headerList is a flag.Value that collects "Key: Value" headers from every
+// use of a repeatable flag. Unlike stringList it never splits on commas,
+// which are common in header values.
+type headerList struct {
+	header http.Header
+}
+
+func (l *headerList) String() string {
+	if l == nil || len(l.header) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	l.header.Write(&b)
+	return strings.TrimSpace(b.String())
+}
+
+func (l *headerList) Set(value string) error {
+	i := strings.Index(value, ":")
+	if i < 0 {
+		return fmt.Errorf("want Key: Value, got %q", value)
+	}
+	key, val := strings.TrimSpace(value[:i]), strings.TrimSpace(value[i+1:])
+	if !validHeaderKey(key) {
+		return fmt.Errorf("invalid header name %q", key)
+	}
+	if strings.ContainsAny(val, "\r\n") {
+		return fmt.Errorf("header %s: value contains a line break", key)
+	}
+
+	if l.header == nil {
+		l.header = make(http.Header)
+	}
+	l.header.Add(key, val)
+	return nil
+}
+
+// validHeaderKey reports whether key is a non-empty HTTP token.
+func validHeaderKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		if r > 0x7e || r <= ' ' || strings.ContainsRune(`"(),/:;<=>?@[\]{}`, r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"strconv"
+	"strings"
+
+	"example/start/pokeapi"
+	"golang.org/x/image/draw"
+)
+
+const jpegQuality = 90
+
+// spriteExtensions maps each supported -sprite-format value to the file
+// extension used when saving.
+var spriteExtensions = map[string]string{
+	"png":  ".png",
+	"jpeg": ".jpg",
+}
+
+// resizeScalers maps each supported -resize-filter value to its scaler.
+// Nearest neighbour keeps pixel art sharp; the others smooth it.
+var resizeScalers = map[string]draw.Scaler{
+	"nearest":    draw.NearestNeighbor,
+	"bilinear":   draw.BiLinear,
+	"catmullrom": draw.CatmullRom,
+}
+
+// parseSize parses a -resize value of the form WxH, Wx or xH. A missing
+// dimension is returned as 0 and later derived from the aspect ratio.
+func parseSize(s string) (int, int, error) {
+	parts := strings.Split(strings.ToLower(s), "x")
+	if len(parts) != 2 || parts[0] == "" && parts[1] == "" {
+		return 0, 0, fmt.Errorf("invalid size %q: want WxH, Wx or xH", s)
+	}
+
+	dims := make([]int, 2)
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || n <= 0 {
+			return 0, 0, fmt.Errorf("invalid size %q: dimensions must be positive integers", s)
+		}
+		dims[i] = n
+	}
+	return dims[0], dims[1], nil
+}
+
+// resizeImage scales src to width by height, deriving whichever of the two
+// is 0 from src's aspect ratio.
+func resizeImage(src image.Image, width, height int, scaler draw.Scaler) image.Image {
+	bounds := src.Bounds()
+	switch {
+	case width == 0:
+		width = bounds.Dx() * height / bounds.Dy()
+	case height == 0:
+		height = bounds.Dy() * width / bounds.Dx()
+	}
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	scaler.Scale(dst, dst.Bounds(), src, bounds, draw.Src, nil)
+	return dst
+}
+
+// decodeErrorPolicies are the -on-decode-error choices for a sprite that
+// fails to validate or convert: save the bytes as downloaded, skip the
+// sprite, or count it as a failure.
+var decodeErrorPolicies = map[string]bool{"raw": true, "skip": true, "fail": true}
+
+// prepareSprite validates and converts a downloaded sprite, applying the
+// -on-decode-error policy when either step fails. It returns the data to save,
+// or false when the sprite should be skipped. With the "fail" policy the
+// download pool has already validated the sprite.
+func prepareSprite(job downloadJob, data []byte, opts spriteOptions) ([]byte, bool, error) {
+	var err error
+	if opts.onDecodeError != "fail" {
+		err = pokeapi.ValidateSprite(job.url, data)
+	}
+	converted := data
+	if err == nil {
+		converted, err = convertSprite(data, opts)
+	}
+	if err == nil {
+		return converted, true, nil
+	}
+
+	switch opts.onDecodeError {
+	case "raw":
+		logInfo("Warning: %s: %v; saving it as downloaded", job, err)
+		return data, true, nil
+	case "skip":
+		logInfo("Skipping %s: %v", job, err)
+		return nil, false, nil
+	}
+	return nil, false, err
+}
+
+// convertSprite re-encodes PNG sprite data into opts.format, resizing it first
+// when opts asks for a size. PNG with no resize, and any data that isn't a PNG
+// to begin with, is passed through untouched. JPEG has no alpha channel, so
+// transparent pixels are composited onto a white background first.
+func convertSprite(data []byte, opts spriteOptions) ([]byte, error) {
+	resize := opts.width > 0 || opts.height > 0
+	if !pokeapi.IsPNG(data) || opts.format == "png" && !resize {
+		return data, nil
+	}
+
+	src, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding sprite: %v", err)
+	}
+	if resize {
+		src = resizeImage(src, opts.width, opts.height, resizeScalers[opts.filter])
+	}
+
+	var buf bytes.Buffer
+	switch opts.format {
+	case "png":
+		if err := png.Encode(&buf, src); err != nil {
+			return nil, fmt.Errorf("error encoding PNG: %v", err)
+		}
+	case "jpeg":
+		bounds := src.Bounds()
+		dst := image.NewRGBA(bounds)
+		draw.Draw(dst, bounds, image.White, image.Point{}, draw.Src)
+		draw.Draw(dst, bounds, src, bounds.Min, draw.Over)
+
+		if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, fmt.Errorf("error encoding JPEG: %v", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported sprite format %q", opts.format)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"example/start/pokeapi"
+)
+
+// inputLabel names an -input file in messages.
+func inputLabel(filename string) string {
+	if filename == "-" {
+		return "stdin"
+	}
+	return filename
+}
+
+// readNameFile reads one Pokemon name or ID per line from filename, or from
+// stdin when filename is "-". Blank lines and anything after a # are
+// ignored. Besides the normalized names it returns the line each one first
+// appeared on, so failures can point back into the file.
+func readNameFile(filename string) ([]string, map[string]int, error) {
+	var r io.Reader = os.Stdin
+	if filename != "-" {
+		file, err := os.Open(filename)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error opening input file: %v", err)
+		}
+		defer file.Close()
+		r = file
+	}
+
+	var names []string
+	lines := make(map[string]int)
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		text := scanner.Text()
+		if i := strings.IndexByte(text, '#'); i >= 0 {
+			text = text[:i]
+		}
+
+		name := pokeapi.NormalizeName(text)
+		if name == "" {
+			continue
+		}
+		if err := pokeapi.ValidateID(name); err != nil {
+			return nil, nil, fmt.Errorf("%s line %d: %v", inputLabel(filename), n, err)
+		}
+
+		names = append(names, name)
+		if _, ok := lines[name]; !ok {
+			lines[name] = n
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error reading input file: %v", err)
+	}
+
+	return names, lines, nil
+}
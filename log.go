@@ -0,0 +1,44 @@
+package main
+
+import (
+	"log"
+	"os"
+)
+
+type logLevel int
+
+const (
+	levelQuiet logLevel = iota
+	levelNormal
+	levelVerbose
+)
+
+// Status messages go to stderr so stdout only ever carries Pokemon data.
+var (
+	logger    = log.New(os.Stderr, "", 0)
+	verbosity = levelNormal
+)
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+func logError(format string, args ...interface{}) {
+	logger.Printf(format, args...)
+}
+
+func logInfo(format string, args ...interface{}) {
+	if verbosity >= levelNormal {
+		logger.Printf(format, args...)
+	}
+}
+
+func logVerbose(format string, args ...interface{}) {
+	if verbosity >= levelVerbose {
+		logger.Printf(format, args...)
+	}
+}
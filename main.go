@@ -1,177 +1,1246 @@
 package main
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
+	"path"
 	"path/filepath"
-	"sync"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"example/start/pokeapi"
+	"golang.org/x/time/rate"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	apiBaseEnv       = "GOPOKE_API_BASE"
+	defaultListLimit = 20
+	defaultRate      = 5
+	defaultJitter    = 200 * time.Millisecond
+
+	// spriteTimeoutFactor sets the default -sprite-timeout relative to
+	// -timeout.
+	spriteTimeoutFactor = 4
+
+	// maxRandomID is the highest national Pokedex number -random picks from.
+	maxRandomID = 1025
 )
 
-type Stat struct {
-	Name string `json:"name"`
-	URL  string `json:"url"`
+// writeFileAtomic writes data to a temporary file next to filename and
+// renames it into place, so an interrupted write never leaves a truncated
+// file behind under the final name. It returns the hex SHA-256 of what was
+// written, hashed on the way to disk.
+func writeFileAtomic(filename string, data []byte) (string, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("error creating file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	if _, err := io.MultiWriter(tmp, hash).Write(data); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("error writing file: %v", err)
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("error writing file: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("error writing file: %v", err)
+	}
+
+	if err := os.Rename(tmp.Name(), filename); err != nil {
+		return "", fmt.Errorf("error renaming file: %v", err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
 }
 
-type StatInfo struct {
-	Stat     Stat  `json:"stat"`
-	BaseStat int32 `json:"base_stat"`
+func saveSprite(data []byte, filename string) error {
+	// With -subdirs each Pokemon has its own directory under the output one.
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return fmt.Errorf("error creating directory: %v", err)
+	}
+	sum, err := writeFileAtomic(filename, data)
+	if err != nil {
+		return fmt.Errorf("error saving sprite: %v", err)
+	}
+
+	return recordChecksum(filename, sum)
 }
 
-type Sprites struct {
-	FrontDefault string `json:"front_default"`
-	BackDefault  string `json:"back_default"`
+// writeMetaFile saves v as indented JSON, the same document -format json
+// prints.
+func writeMetaFile(filename string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding JSON: %v", err)
+	}
+
+	sum, err := writeFileAtomic(filename, append(data, '\n'))
+	if err != nil {
+		return fmt.Errorf("error saving metadata: %v", err)
+	}
+	return recordChecksum(filename, sum)
 }
 
-type Pokemon struct {
-	Name     string     `json:"name"`
-	BaseExp  int32      `json:"base_experience"`
-	Height   int32      `json:"height"`
-	Id       int32      `json:"id"`
-	Sprites  Sprites    `json:"sprites"`
-	StatInfo []StatInfo `json:"stats"`
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: gopoke [flags] <name|id>...\n")
+	fmt.Fprintf(os.Stderr, "       gopoke -compare <name|id> <name|id>\n")
+	fmt.Fprintf(os.Stderr, "       gopoke -list [-limit n] [-offset n] [-filter-type type]\n")
+	fmt.Fprintf(os.Stderr, "       gopoke -random [-seed n]\n")
+	fmt.Fprintf(os.Stderr, "       gopoke -ping\n\n")
+	fmt.Fprintf(os.Stderr, "Fetches one or more Pokemon by name (e.g. pikachu) or Pokedex ID (e.g. 25).\n\n")
+	fmt.Fprintf(os.Stderr, "Flags:\n")
+	flag.PrintDefaults()
+	fmt.Fprintf(os.Stderr, "\nEnvironment:\n")
+	fmt.Fprintf(os.Stderr, "  %s\n    \tAPI base URL, used when -api-base is not given (default %s)\n", apiBaseEnv, pokeapi.DefaultBaseURL)
+	fmt.Fprintf(os.Stderr, "  HTTP_PROXY, HTTPS_PROXY, NO_PROXY\n    \tproxy settings, used when -proxy is not given\n")
 }
 
-func fetchData(url string, wg *sync.WaitGroup, resultChan chan<- []byte, errorChan chan<- error) {
-	defer wg.Done()
+// resolveBaseURL picks the API base URL from the flag, then the environment,
+// then the built-in default, and normalizes it so paths can be appended.
+func resolveBaseURL(flagValue string) (string, error) {
+	base := flagValue
+	if base == "" {
+		base = os.Getenv(apiBaseEnv)
+	}
+	if base == "" {
+		base = pokeapi.DefaultBaseURL
+	}
 
-	client := http.DefaultClient
-	resp, err := client.Get(url)
+	u, err := url.Parse(base)
 	if err != nil {
-		errorChan <- fmt.Errorf("HTTP request error: %v", err)
-		return
+		return "", fmt.Errorf("invalid API base URL %q: %v", base, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("invalid API base URL %q: must be an absolute URL", base)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		errorChan <- fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-		return
+	return strings.TrimRight(base, "/"), nil
+}
+
+// randomID picks a Pokedex number from 1 to maxRandomID. A non-zero seed
+// always picks the same one; zero seeds from the clock.
+func randomID(seed int64) int {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
 	}
+	return rand.New(rand.NewSource(seed)).Intn(maxRandomID) + 1
+}
 
-	body, err := io.ReadAll(resp.Body)
+// proxyClient returns an HTTP client that sends every request through the
+// proxy at rawURL instead of the one named by HTTP_PROXY and HTTPS_PROXY.
+func proxyClient(rawURL string) (*http.Client, error) {
+	u, err := url.Parse(rawURL)
 	if err != nil {
-		errorChan <- fmt.Errorf("error reading response body: %v", err)
-		return
+		return nil, fmt.Errorf("invalid proxy URL %q: %v", rawURL, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q: must be an absolute URL such as http://proxy:8080", rawURL)
 	}
 
-	resultChan <- body
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyURL(u)
+	return &http.Client{Transport: transport}, nil
 }
 
-func parseJSON(body []byte) (Pokemon, error) {
-	var data Pokemon
-	err := json.Unmarshal(body, &data)
+// printJSON writes v as indented JSON, or on a single line when -pretty is
+// off.
+func printJSON(w io.Writer, v interface{}) error {
+	if !prettyJSON {
+		return printJSONLine(w, v)
+	}
+
+	data, err := json.MarshalIndent(v, "", "  ")
 	if err != nil {
-		return Pokemon{}, fmt.Errorf("error parsing JSON: %v", err)
+		return fmt.Errorf("error encoding JSON: %v", err)
 	}
 
-	return data, nil
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+func printYAML(w io.Writer, v interface{}) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("error encoding YAML: %v", err)
+	}
+	return enc.Close()
 }
 
-func downloadSprite(url string) ([]byte, error) {
-	resp, err := http.Get(url)
+// printJSONLine writes v as a single line of compact JSON.
+func printJSONLine(w io.Writer, v interface{}) error {
+	data, err := json.Marshal(v)
 	if err != nil {
-		return nil, fmt.Errorf("error downloading sprite: %v", err)
+		return fmt.Errorf("error encoding JSON: %v", err)
+	}
+
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// printStructured writes v in one of the machine-readable output formats.
+func printStructured(w io.Writer, format string, v interface{}) error {
+	if format == "yaml" {
+		return printYAML(w, v)
 	}
-	defer resp.Body.Close()
+	return printJSON(w, v)
+}
+
+type spriteRef struct {
+	label string
+	url   string
+}
+
+type spriteOptions struct {
+	shiny   bool
+	artwork bool
+	all     bool
+	format  string
+	subdirs bool
+
+	// width and height are the -resize dimensions; 0 means "keep the
+	// aspect ratio", and both 0 means no resize at all.
+	width  int
+	height int
+	filter string
+
+	onDecodeError string
+
+	// template, when set, names every saved file instead of the built-in
+	// scheme.
+	template *template.Template
+}
+
+// allSpriteRefs lists every sprite in the response, labelled by its JSON path
+// with slashes turned into underscores, in a stable order.
+func allSpriteRefs(sprites pokeapi.Sprites) []spriteRef {
+	urls := sprites.URLs()
+	paths := make([]string, 0, len(urls))
+	for p := range urls {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	refs := make([]spriteRef, len(paths))
+	for i, p := range paths {
+		refs[i] = spriteRef{strings.ReplaceAll(p, "/", "_"), urls[p]}
+	}
+	return refs
+}
+
+func spriteJobs(pokemon pokeapi.Pokemon, outputDir string, opts spriteOptions) []downloadJob {
+	sprites := []spriteRef{
+		{"front", pokemon.Sprites.FrontDefault},
+		{"back", pokemon.Sprites.BackDefault},
+	}
+	if opts.shiny {
+		sprites = append(sprites,
+			spriteRef{"front_shiny", pokemon.Sprites.FrontShiny},
+			spriteRef{"back_shiny", pokemon.Sprites.BackShiny},
+		)
+	}
+	if opts.artwork {
+		sprites = append(sprites, spriteRef{"artwork", pokemon.Sprites.Other.OfficialArtwork.FrontDefault})
+	}
+	if opts.all {
+		sprites = allSpriteRefs(pokemon.Sprites)
+	}
+
+	var jobs []downloadJob
+	for _, sprite := range sprites {
+		if sprite.url == "" {
+			continue
+		}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		// Only PNG sprites are re-encoded; animated GIFs and SVGs found by
+		// -all-sprites keep their own extension.
+		ext := strings.ToLower(path.Ext(sprite.url))
+		if ext == "" || ext == ".png" {
+			ext = spriteExtensions[opts.format]
+		}
+
+		filename, err := jobFileName(outputDir, pokemon, sprite.label, ext, opts)
+		if err != nil {
+			logError("Error naming %s %s sprite: %v", pokemon.Name, sprite.label, err)
+			continue
+		}
+
+		jobs = append(jobs, downloadJob{
+			pokemon:  pokemon.Name,
+			label:    sprite.label,
+			url:      sprite.url,
+			filename: filename,
+		})
 	}
 
-	spriteData, err := io.ReadAll(resp.Body)
+	return jobs
+}
+
+// cryJob returns the job that saves the Pokemon's latest cry, if it has one.
+func cryJob(pokemon pokeapi.Pokemon, outputDir string, opts spriteOptions) (downloadJob, bool) {
+	if pokemon.Cries.Latest == "" {
+		return downloadJob{}, false
+	}
+
+	ext := path.Ext(pokemon.Cries.Latest)
+	if ext == "" {
+		ext = ".ogg"
+	}
+	filename, err := jobFileName(outputDir, pokemon, "cry", ext, opts)
 	if err != nil {
-		return nil, fmt.Errorf("error reading sprite data: %v", err)
+		logError("Error naming %s cry: %v", pokemon.Name, err)
+		return downloadJob{}, false
 	}
 
-	return spriteData, nil
+	return downloadJob{
+		pokemon:  pokemon.Name,
+		label:    "cry",
+		url:      pokemon.Cries.Latest,
+		filename: filename,
+		raw:      true,
+	}, true
 }
 
-func saveSprite(data []byte, filename string) error {
-	file, err := os.Create(filename)
+// skipExisting drops jobs whose target file is already on disk so repeated
+// runs don't clobber previously saved sprites.
+func skipExisting(jobs []downloadJob) []downloadJob {
+	var kept []downloadJob
+	for _, job := range jobs {
+		if _, err := os.Stat(job.filename); err == nil {
+			logInfo("Skipping %s: %s already exists (use -force to overwrite)", job, job.filename)
+			continue
+		}
+		kept = append(kept, job)
+	}
+	return kept
+}
+
+// printFlavorText prints the Pokemon's English Pokedex entry, reporting
+// whether the species could be fetched.
+func printFlavorText(ctx context.Context, client *pokeapi.Client, pokemon pokeapi.Pokemon, timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	species, err := client.GetSpecies(ctx, pokemon)
 	if err != nil {
-		return fmt.Errorf("error creating file: %v", err)
+		logError("Error fetching species for %s: %v", pokemon.Name, err)
+		return false
+	}
+
+	text, ok := species.FlavorText("en")
+	if !ok {
+		logInfo("No English Pokedex entry for %s", pokemon.Name)
+		return true
 	}
-	defer file.Close()
+	fmt.Fprintln(stdout, "Pokemon Description:", text)
+	return true
+}
+
+// printEvolution prints every evolutionary line the Pokemon belongs to,
+// reporting whether its species and evolution chain could be fetched.
+func printEvolution(ctx context.Context, client *pokeapi.Client, pokemon pokeapi.Pokemon, timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	_, err = file.Write(data)
+	species, err := client.GetSpecies(ctx, pokemon)
 	if err != nil {
-		return fmt.Errorf("error saving sprite: %v", err)
+		logError("Error fetching species for %s: %v", pokemon.Name, err)
+		return false
+	}
+	chain, err := client.GetEvolutionChain(ctx, species)
+	if err != nil {
+		logError("Error fetching evolution chain for %s: %v", pokemon.Name, err)
+		return false
+	}
+
+	lines := chain.Lines()
+	if len(lines) == 1 {
+		fmt.Fprintln(stdout, "Evolution:", strings.Join(lines[0], " -> "))
+		return true
+	}
+	fmt.Fprintln(stdout, "Evolution:")
+	for _, line := range lines {
+		fmt.Fprintln(stdout, "  "+strings.Join(line, " -> "))
+	}
+	return true
+}
+
+func printPlan(w io.Writer, jobs []downloadJob) {
+	for _, job := range jobs {
+		fmt.Fprintf(w, "Would download %s from %s to %s\n", job, job.url, job.filename)
+	}
+}
+
+// spriteURL is one entry of -print-urls output in a structured format.
+type spriteURL struct {
+	Name   string `json:"name" yaml:"name"`
+	Sprite string `json:"sprite" yaml:"sprite"`
+	URL    string `json:"url" yaml:"url"`
+}
+
+// printSpriteURLs writes the URL of every job, one per line in the text
+// formats so the output can be fed straight to a downloader.
+func printSpriteURLs(w io.Writer, format string, jobs []downloadJob) error {
+	urls := make([]spriteURL, len(jobs))
+	for i, job := range jobs {
+		urls[i] = spriteURL{job.pokemon, job.label, job.url}
+	}
+
+	switch format {
+	case "json", "yaml":
+		return printStructured(w, format, urls)
+	case "jsonl":
+		for _, u := range urls {
+			if err := printJSONLine(w, u); err != nil {
+				return err
+			}
+		}
+		return nil
 	}
 
+	for _, u := range urls {
+		if _, err := fmt.Fprintln(w, u.URL); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func main() {
-	var id int
-	fmt.Print("Enter Pokemon ID: ")
-	_, err := fmt.Scanf("%d", &id)
-	if err != nil {
-		fmt.Println("Error reading input:", err)
-		return
+// saveSpriteResult converts and saves one downloaded job, logging the outcome.
+func saveSpriteResult(result downloadResult, opts spriteOptions, counts *runCounts) bool {
+	job := result.job
+	if result.err != nil {
+		logError("Error downloading %s: %v", job, result.err)
+		emitEvent(progressEvent{Event: "sprite_failed", Name: job.pokemon, Sprite: job.label, Error: result.err.Error()})
+		return false
+	}
+	if result.reused {
+		logInfo("Reusing the download of %s for %s", job.url, job)
+	}
+
+	data := result.data
+	if !job.raw {
+		var save bool
+		var err error
+		data, save, err = prepareSprite(job, data, opts)
+		if err != nil {
+			logError("Error converting %s: %v", job, err)
+			emitEvent(progressEvent{Event: "sprite_failed", Name: job.pokemon, Sprite: job.label, Error: err.Error()})
+			return false
+		}
+		if !save {
+			return true
+		}
+	}
+
+	if err := saveSprite(data, job.filename); err != nil {
+		logError("Error saving %s: %v", job, err)
+		emitEvent(progressEvent{Event: "sprite_failed", Name: job.pokemon, Sprite: job.label, Error: err.Error()})
+		return false
 	}
+	logInfo("%s saved as: %s", job, job.filename)
+	emitEvent(progressEvent{Event: "sprite_saved", Name: job.pokemon, Sprite: job.label, File: job.filename})
+	counts.saved(len(data))
+	return true
+}
 
-	url := fmt.Sprintf("https://pokeapi-proxy.freecodecamp.rocks/api/pokemon/%d/", id)
+// saveSprites downloads, converts and saves every job, returning how many
+// failed. Downloads run concurrently, but results are handled in job order,
+// so the log reads grouped by Pokemon and sprite however the downloads
+// finish: a finished result waits until every job before it is done.
+func saveSprites(ctx context.Context, pool *downloadPool, jobs []downloadJob, opts spriteOptions, counts *runCounts) int {
+	failed := 0
+	pending := make(map[int]downloadResult)
+	next := 0
+	for result := range pool.Run(ctx, jobs) {
+		pending[result.index] = result
+		for {
+			result, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			if !saveSpriteResult(result, opts, counts) {
+				failed++
+			}
+		}
+	}
 
-	var wg sync.WaitGroup
-	resultChan := make(chan []byte)
-	errorChan := make(chan error)
+	return failed
+}
 
-	wg.Add(1)
-	go fetchData(url, &wg, resultChan, errorChan)
+// exitInterrupted is the conventional status for a process stopped by SIGINT.
+const exitInterrupted = 130
 
+// handleInterrupt cancels the run on the first Ctrl-C so in-flight requests
+// abort and main can exit once pending writes have been cleaned up. A second
+// Ctrl-C falls back to the default behaviour and kills the process.
+func handleInterrupt(cancel context.CancelFunc) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt)
 	go func() {
-		wg.Wait()
-		close(resultChan)
-		close(errorChan)
+		<-sigs
+		signal.Stop(sigs)
+		logError("Interrupted, cancelling requests")
+		cancel()
 	}()
+}
+
+// runPing reports whether the API answers and how quickly, returning the
+// exit status.
+func runPing(ctx context.Context, client *pokeapi.Client, timeout time.Duration) int {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	elapsed, err := client.Ping(ctx)
+	if err != nil {
+		logError("API at %s is unreachable: %v", client.BaseURL, err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "API at %s is reachable (%v)\n", client.BaseURL, elapsed.Round(time.Millisecond))
+	return 0
+}
+
+// runList prints one page of the Pokemon index, returning the exit status.
+func runList(ctx context.Context, client *pokeapi.Client, limit, offset int, typeName, format string, timeout time.Duration) int {
+	listCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	list, err := client.ListPokemon(listCtx, limit, offset)
+	if err != nil {
+		logError("Error listing Pokemon: %v", err)
+		return 1
+	}
+
+	status := 0
+	shown := len(list.Results)
+	if typeName != "" {
+		var ok bool
+		list.Results, ok = filterByType(ctx, client, list.Results, typeName, timeout)
+		if !ok {
+			status = 1
+		}
+	}
+
+	if format == "jsonl" {
+		for _, entry := range list.Results {
+			if err := printJSONLine(stdout, entry); err != nil {
+				logError("Error: %v", err)
+				return 1
+			}
+		}
+		return status
+	}
+	if format == "json" || format == "yaml" {
+		if err := printStructured(stdout, format, list.Results); err != nil {
+			logError("Error: %v", err)
+			return 1
+		}
+		return status
+	}
+
+	for _, entry := range list.Results {
+		fmt.Fprintf(stdout, "%5d  %s\n", entry.ID(), entry.Name)
+	}
+	switch {
+	case typeName != "":
+		logInfo("Found %d %s type Pokemon in %d-%d of %d", len(list.Results), typeName, offset+1, offset+shown, list.Count)
+	case len(list.Results) > 0:
+		logInfo("Showing %d-%d of %d", offset+1, offset+len(list.Results), list.Count)
+	}
+	return status
+}
+
+// filterByType fetches every entry to learn its types and keeps those of
+// typeName, in their original order. It reports whether every fetch
+// succeeded; entries that couldn't be fetched are left out.
+func filterByType(ctx context.Context, client *pokeapi.Client, entries []pokeapi.PokemonListEntry, typeName string, timeout time.Duration) ([]pokeapi.PokemonListEntry, bool) {
+	logInfo("Fetching %d Pokemon to check their types; this makes one request each and may take a while", len(entries))
+
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.Name
+	}
+	results, _ := fetchAll(ctx, client, names, timeout, false)
+
+	ok := true
+	var kept []pokeapi.PokemonListEntry
+	for i, result := range results {
+		if result.err != nil {
+			logError("Error fetching %s: %v", result.name, result.err)
+			ok = false
+			continue
+		}
+		for _, t := range result.pokemon.TypeNames() {
+			if t == typeName {
+				kept = append(kept, entries[i])
+				break
+			}
+		}
+	}
+	return kept, ok
+}
+
+// runCompare fetches both Pokemon and prints their comparison table,
+// returning the exit status.
+func runCompare(ctx context.Context, client *pokeapi.Client, names []string, timeout time.Duration) int {
+	results, err := fetchAll(ctx, client, names, timeout, true)
+	if err != nil {
+		for _, result := range results {
+			if result.err != nil {
+				logError("Error fetching %s: %v", result.name, result.err)
+			}
+		}
+		return 1
+	}
+
+	if err := printComparison(stdout, results[0].pokemon, results[1].pokemon); err != nil {
+		logError("Error: %v", err)
+		return 1
+	}
+	return 0
+}
+
+func main() {
+	configFile := flag.String("config", "", "read default flag values from this YAML or JSON file (default "+defaultConfigFile+" if present)")
+	inputFile := flag.String("input", "", "also fetch the Pokemon named in this `file`, one per line (- reads stdin; blank lines and # comments are skipped)")
+	outputDir := flag.String("output", ".", "directory to save sprites into")
+	pretty := flag.Bool("pretty", isTerminal(os.Stdout), "indent -format json output; the default is to indent only when writing to a terminal")
+	outputFile := flag.String("o", "", "write the printed output to this `file` instead of stdout, replacing it once the run is done")
+	timeout := flag.Duration("timeout", 30*time.Second, "timeout for each API request")
+	spriteTimeout := flag.Duration("sprite-timeout", 0, "timeout for each sprite or cry download (default 4 times -timeout)")
+	retries := flag.Int("retries", pokeapi.DefaultRetries, "number of times to retry a request on network errors, 5xx responses or 429 Too Many Requests; a 429 waits as long as its Retry-After header asks")
+	rateLimit := flag.Float64("rate", defaultRate, "maximum requests per second to the API and sprite hosts (0 disables the limit)")
+	jitter := flag.Duration("jitter", defaultJitter, "wait a random time up to this long before each request to spread out batches (0 disables)")
+	concurrency := flag.Int("concurrency", defaultConcurrency, "maximum number of sprite downloads to run at once")
+	format := flag.String("format", "text", "output format: text, table, json, jsonl (one object per line as each fetch completes) or yaml")
+	flattenStats := flag.Bool("flatten-stats", false, "also give each stat as a top-level field such as hp and special_attack in JSON and YAML output")
+	flattenOnly := flag.Bool("flatten-only", false, "like -flatten-stats, but leave out the stats array")
+	noSprites := flag.Bool("no-sprites", false, "only fetch and print Pokemon data, without downloading any sprites")
+	fileNameTemplate := flag.String("filename-template", "", "name saved files with this Go `template`, relative to -output, e.g. {{.Id}}/{{.Side}}_{{.Variant}}{{.Ext}} (fields: Name, Id, Label, Side, Variant, Ext)")
+	subdirs := flag.Bool("subdirs", false, "save each Pokemon's sprites in its own directory, as <output>/<name>/front.png")
+	embed := flag.Bool("embed-sprites", false, "include every sprite as a base64 data URI in the JSON or YAML output and -meta file (combine with -no-sprites to skip saving them)")
+	shiny := flag.Bool("shiny", false, "also download the shiny sprite variants")
+	artwork := flag.Bool("artwork", false, "also download the high resolution official artwork")
+	cry := flag.Bool("cry", false, "also download the Pokemon's latest cry as <name>_cry.ogg")
+	allSprites := flag.Bool("all-sprites", false, "download every sprite the API lists, including per-game variants")
+	spriteFormat := flag.String("sprite-format", "png", "format to save sprites in: png or jpeg")
+	resize := flag.String("resize", "", "scale sprites to `WxH` before saving; give only Wx or xH to keep the aspect ratio")
+	resizeFilter := flag.String("resize-filter", "nearest", "scaling filter for -resize: nearest, bilinear or catmullrom")
+	onDecodeError := flag.String("on-decode-error", "fail", "what to do with a sprite that isn't a valid image: raw (save it as downloaded), skip or fail")
+	var ranges idRanges
+	flag.Var(&ranges, "range", "also fetch every Pokemon with a Pokedex ID in this `range`, e.g. 1-151 (repeatable or comma-separated, single IDs allowed)")
+	random := flag.Bool("random", false, fmt.Sprintf("also fetch a random Pokemon from #1 to #%d", maxRandomID))
+	seed := flag.Int64("seed", 0, "seed for -random, so the same seed always picks the same Pokemon (0 picks a different one each run)")
+	compare := flag.Bool("compare", false, "print a side-by-side stat comparison of exactly two Pokemon")
+	ping := flag.Bool("ping", false, "check that the API is reachable, report how long it took and exit")
+	list := flag.Bool("list", false, "list available Pokemon names and IDs instead of fetching details")
+	limit := flag.Int("limit", defaultListLimit, "number of Pokemon to show with -list")
+	offset := flag.Int("offset", 0, "number of Pokemon to skip with -list")
+	prefix := flag.Bool("prefix", false, "treat each name as the start of a Pokemon name, e.g. char, and fetch the one it matches (exits 3 listing the candidates if it matches several)")
+	filterType := flag.String("filter-type", "", "with -list, only show Pokemon of this `type`, e.g. grass (fetches every entry, so it is slower)")
+	var statNames stringList
+	flag.Var(&statNames, "stat", "only show the `stat` with this name, e.g. speed (repeatable or comma-separated; default all)")
+	summary := flag.Bool("summary", false, "after fetching, print the min, mean and max of each stat across the batch and the highest base stat total")
+	count := flag.Bool("count", false, "finish with a one-line key=value summary of the run on stderr (a JSON object with -format json or jsonl)")
+	compact := flag.Bool("compact", false, "print each Pokemon on one line, e.g. #025 pikachu HP:35 ATK:55 DEF:40 SPD:90 BST:320 (text output only)")
+	chart := flag.Bool("chart", false, "also draw the stats as a bar chart (text output only)")
+	moves := flag.Bool("moves", false, "also print the moves the Pokemon can learn (text output only)")
+	movesLimit := flag.Int("moves-limit", 0, "print at most this many moves with -moves (0 prints all)")
+	items := flag.Bool("items", false, "also print the items the Pokemon may hold in the wild (text output only)")
+	forms := flag.Bool("forms", false, "also print the Pokemon's forms, which can be fetched by name (text output only)")
+	evolution := flag.Bool("evolution", false, "also fetch and print the Pokemon's evolutionary line, one per branch (text output only)")
+	flavor := flag.Bool("flavor", false, "also fetch and print the English Pokedex entry (text output only)")
+	failFast := flag.Bool("fail-fast", false, "cancel the remaining fetches and stop as soon as one Pokemon fails")
+	force := flag.Bool("force", false, "overwrite sprite files that already exist")
+	printURLs := flag.Bool("print-urls", false, "print the URL of every sprite that would be downloaded instead of the Pokemon, and download nothing (a list of objects with -format json, jsonl or yaml)")
+	dryRun := flag.Bool("dry-run", false, "fetch and print Pokemon but only show which files would be downloaded and written")
+	metaFile := flag.String("meta", "", "write the full data of every fetched Pokemon to this JSON file")
+	flag.BoolVar(&printChecksums, "checksum", false, "log the SHA-256 of every sprite and metadata file saved, in sha256sum format")
+	flag.BoolVar(&writeChecksums, "checksum-files", false, "write the SHA-256 of every sprite and metadata file saved to a <file>.sha256 sidecar")
+	csvFile := flag.String("csv", "", "write the stats of every fetched Pokemon to this CSV file")
+	noCache := flag.Bool("no-cache", false, "always fetch from the API instead of using cached responses")
+	offline := flag.Bool("offline", false, "never touch the network: serve Pokemon and sprites only from the caches, however old, and fail for anything not cached")
+	noSpriteCache := flag.Bool("no-sprite-cache", false, "always download sprites instead of reusing ones saved by earlier runs")
+	cacheTTL := flag.Duration("cache-ttl", pokeapi.DefaultCacheTTL, "how long cached responses stay fresh (0 never expires)")
+	apiBase := flag.String("api-base", "", "base URL of the PokeAPI server (default "+pokeapi.DefaultBaseURL+")")
+	proxy := flag.String("proxy", "", "send all requests through this HTTP proxy `URL` (default from HTTP_PROXY and HTTPS_PROXY)")
+	maxBody := flag.Int64("max-body", pokeapi.DefaultMaxBodySize, "largest API response to read, in bytes (0 for no limit)")
+	maxRedirects := flag.Int("max-redirects", pokeapi.DefaultMaxRedirects, "most redirects to follow for a single request (0 follows none)")
+	maxSpriteBody := flag.Int64("max-sprite-body", pokeapi.DefaultMaxSpriteSize, "largest sprite to download, in bytes (0 for no limit)")
+	restrictHosts := flag.Bool("restrict-hosts", false, "only download sprites from the API host, "+pokeapi.SpriteHost+" and any -allow-host")
+	var allowHosts stringList
+	flag.Var(&allowHosts, "allow-host", "also allow sprites from this `host` with -restrict-hosts (repeatable or comma-separated)")
+	userAgent := flag.String("user-agent", pokeapi.DefaultUserAgent, "User-Agent header sent with every request")
+	var headers headerList
+	flag.Var(&headers, "header", "add this `\"Key: Value\"` header to every request (repeatable)")
+	showVersion := flag.Bool("version", false, "print version information and exit")
+	progressJSON := flag.Bool("progress-json", false, "report progress as one JSON event per line on stderr instead of a progress bar (combine with -quiet to leave stderr to events and errors)")
+	quiet := flag.Bool("quiet", false, "only log errors")
+	verbose := flag.Bool("verbose", false, "also log resolved URLs and request timings")
+	flag.Usage = usage
+	flag.Parse()
+	counts := newRunCounts()
+
+	if *showVersion {
+		printVersion(os.Stdout)
+		os.Exit(0)
+	}
+
+	if err := applyConfig(*configFile); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	switch *format {
+	case "text", "table", "json", "jsonl", "yaml":
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown format %q\n", *format)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if _, ok := spriteExtensions[*spriteFormat]; !ok {
+		fmt.Fprintf(os.Stderr, "Unknown sprite format %q\n", *spriteFormat)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *embed && *format != "json" && *format != "yaml" && *metaFile == "" {
+		fmt.Fprintln(os.Stderr, "-embed-sprites needs -format json, -format yaml or -meta")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if !decodeErrorPolicies[*onDecodeError] {
+		fmt.Fprintf(os.Stderr, "Unknown -on-decode-error policy %q\n", *onDecodeError)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var fileNames *template.Template
+	if *fileNameTemplate != "" {
+		if *subdirs {
+			fmt.Fprintln(os.Stderr, "-filename-template and -subdirs cannot be used together")
+			flag.Usage()
+			os.Exit(2)
+		}
+		var err error
+		fileNames, err = parseFileNameTemplate(*fileNameTemplate)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "-filename-template: %v\n", err)
+			flag.Usage()
+			os.Exit(2)
+		}
+	}
+
+	if _, ok := resizeScalers[*resizeFilter]; !ok {
+		fmt.Fprintf(os.Stderr, "Unknown resize filter %q\n", *resizeFilter)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *filterType != "" && !*list {
+		fmt.Fprintln(os.Stderr, "-filter-type only works with -list")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *offline && (*noCache || *noSpriteCache) {
+		fmt.Fprintln(os.Stderr, "-offline needs the caches, so it cannot be used with -no-cache or -no-sprite-cache")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *compact && *format != "text" {
+		fmt.Fprintln(os.Stderr, "-compact only works with -format text")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	flatten := *flattenStats || *flattenOnly
+	if flatten && *format != "json" && *format != "jsonl" && *format != "yaml" && *metaFile == "" {
+		fmt.Fprintln(os.Stderr, "-flatten-stats needs -format json, jsonl, yaml or -meta")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *rateLimit < 0 {
+		fmt.Fprintln(os.Stderr, "-rate cannot be negative")
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *maxRedirects < 0 {
+		fmt.Fprintln(os.Stderr, "-max-redirects cannot be negative")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	switch {
+	case *quiet && *verbose:
+		fmt.Fprintln(os.Stderr, "-quiet and -verbose cannot be used together")
+		flag.Usage()
+		os.Exit(2)
+	case *quiet:
+		verbosity = levelQuiet
+	case *verbose:
+		verbosity = levelVerbose
+	}
+
+	var names []string
+	for _, arg := range flag.Args() {
+		name := pokeapi.NormalizeName(arg)
+		if name == "" {
+			continue
+		}
+		if err := pokeapi.ValidateID(name); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		names = append(names, name)
+	}
+	var inputLines map[string]int
+	if *inputFile != "" {
+		fromFile, lines, err := readNameFile(*inputFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		names = append(names, fromFile...)
+		inputLines = lines
+	}
+	names = append(names, ranges.ids...)
+	if *random {
+		id := randomID(*seed)
+		logInfo("Picked random Pokemon #%d", id)
+		names = append(names, strconv.Itoa(id))
+	}
+	if len(names) == 0 && !*list && !*ping {
+		flag.Usage()
+		os.Exit(2)
+	}
 
-	select {
-	case err := <-errorChan:
-		fmt.Println("Error:", err)
-	case body := <-resultChan:
-		pokemon, err := parseJSON(body)
+	if *compare && len(names) != 2 {
+		fmt.Fprintln(os.Stderr, "-compare needs exactly two Pokemon")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if !*compare {
+		var dupes []string
+		names, dupes = dedupeNames(names)
+		for _, name := range dupes {
+			logInfo("Ignoring duplicate argument %s", name)
+		}
+	}
+
+	if !*dryRun && !*noSprites && !*printURLs {
+		if err := os.MkdirAll(*outputDir, 0755); err != nil {
+			logError("Error creating output directory: %v", err)
+			os.Exit(1)
+		}
+	}
+
+	baseURL, err := resolveBaseURL(*apiBase)
+	if err != nil {
+		logError("Error: %v", err)
+		os.Exit(2)
+	}
+
+	printOpts := pokeapi.PrintOptions{Format: "text", Moves: *moves, MovesLimit: *movesLimit, Items: *items, Forms: *forms}
+	spriteOpts := spriteOptions{shiny: *shiny, artwork: *artwork, all: *allSprites, format: *spriteFormat, subdirs: *subdirs, filter: *resizeFilter, onDecodeError: *onDecodeError, template: fileNames}
+	if *resize != "" {
+		spriteOpts.width, spriteOpts.height, err = parseSize(*resize)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "-resize: %v\n", err)
+			flag.Usage()
+			os.Exit(2)
+		}
+	}
+
+	var clientOpts []pokeapi.Option
+	if *proxy != "" {
+		hc, err := proxyClient(*proxy)
 		if err != nil {
-			fmt.Println("Error:", err)
+			logError("Error: %v", err)
+			os.Exit(2)
+		}
+		clientOpts = append(clientOpts, pokeapi.WithHTTPClient(hc))
+	}
+
+	client := pokeapi.NewClient(baseURL, clientOpts...)
+	client.Retries = *retries
+	client.UserAgent = *userAgent
+	client.Header = headers.header
+	client.CacheTTL = *cacheTTL
+	client.Logf = logInfo
+	client.Debugf = logVerbose
+	if *progressJSON {
+		eventOut = os.Stderr
+	} else if verbosity >= levelNormal && isTerminal(os.Stderr) {
+		client.Progress = os.Stderr
+	}
+	client.CacheReadOnly = *dryRun
+	client.Offline = *offline
+	client.Jitter = *jitter
+	client.MaxBodySize = *maxBody
+	client.MaxSpriteSize = *maxSpriteBody
+	client.MaxRedirects = *maxRedirects
+	if *restrictHosts {
+		apiURL, _ := url.Parse(baseURL)
+		client.SpriteHosts = append([]string{apiURL.Hostname(), pokeapi.SpriteHost}, allowHosts...)
+	}
+	if *rateLimit > 0 {
+		client.Limiter = rate.NewLimiter(rate.Limit(*rateLimit), 1)
+	}
+	if !*noCache {
+		client.Cache = pokeapi.NewDiskCache(pokeapi.DefaultCacheDir())
+	}
+	if !*noSpriteCache {
+		client.SpriteCache = pokeapi.NewDiskCache(filepath.Join(pokeapi.DefaultCacheDir(), "sprites"))
+	}
+
+	if *outputFile != "" {
+		redirectOutput(*outputFile)
+		if !explicitFlag("pretty") {
+			*pretty = false
+		}
+	}
+	prettyJSON = *pretty
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	handleInterrupt(cancel)
+
+	if *ping {
+		exit(runPing(ctx, client, *timeout))
+	}
+
+	if *list {
+		exit(runList(ctx, client, *limit, *offset, strings.ToLower(*filterType), *format, *timeout))
+	}
+
+	if *prefix {
+		var status int
+		names, status = resolvePrefixes(ctx, client, names, *timeout)
+		if status != 0 {
+			exit(status)
+		}
+		if !*compare {
+			var dupes []string
+			names, dupes = dedupeNames(names)
+			for _, name := range dupes {
+				logInfo("Ignoring duplicate match %s", name)
+			}
+		}
+	}
+
+	if *compare {
+		exit(runCompare(ctx, client, names, *timeout))
+	}
+
+	// Artwork can take far longer to transfer than a JSON response.
+	if *spriteTimeout <= 0 {
+		*spriteTimeout = spriteTimeoutFactor * *timeout
+	}
+	pool := newDownloadPool(client, *concurrency, *spriteTimeout)
+	pool.lenient = *onDecodeError != "fail"
+
+	// Sprites that will be downloaded start as soon as their Pokemon is
+	// handled, overlapping with the rest of the batch's fetches.
+	prefetch := func(job downloadJob) {
+		switch {
+		case *dryRun || *printURLs:
+			return
+		case *embed && !job.raw:
+		case *noSprites:
 			return
+		case !*force:
+			if _, err := os.Stat(job.filename); err == nil {
+				return
+			}
+		}
+		pool.prefetch(ctx, job)
+	}
+
+	// Any failure below is reported as it happens and reflected in the exit
+	// status once everything that could be done has been done.
+	hadErrors := false
+	failed := 0
+	collapsed := 0
+	var fetched []pokeapi.Pokemon
+	var jobs []downloadJob
+	// A name and an ID can only be matched up once both have been fetched.
+	seenIDs := make(map[int32]string)
+	var completed []fetchResult
+	handle := func(i int, result fetchResult) {
+		completed = append(completed, result)
+		if result.err == nil {
+			if first, ok := seenIDs[result.pokemon.Id]; ok {
+				logInfo("Ignoring %s: same Pokemon as %s", result.name, first)
+				collapsed++
+				return
+			}
+			seenIDs[result.pokemon.Id] = result.name
+		}
+
+		if *format == "text" && len(names) > 1 && !*printURLs && !*compact {
+			if i > 0 {
+				fmt.Fprintln(stdout)
+			}
+			fmt.Fprintf(stdout, "== %s ==\n", result.name)
 		}
-		fmt.Println("Pokemon Name:", pokemon.Name)
-		fmt.Println("Pokemon BaseExp:", pokemon.BaseExp)
-		fmt.Println("Pokemon Height:", pokemon.Height)
-		fmt.Println("Pokemon Id:", pokemon.Id)
-		fmt.Println("Pokemon Sprites:", pokemon.Sprites)
-		fmt.Println("Pokemon Abilities:", pokemon.StatInfo)
-
-		// Download and save sprites
-		if pokemon.Sprites.FrontDefault != "" {
-			frontFilename := filepath.Join(".", fmt.Sprintf("%s_front.png", pokemon.Name))
-			spriteData, err := downloadSprite(pokemon.Sprites.FrontDefault)
-			if err != nil {
-				fmt.Println("Error downloading front sprite:", err)
+
+		if result.err != nil {
+			if line, ok := inputLines[result.name]; ok {
+				logError("Error fetching %s (%s line %d): %v", result.name, inputLabel(*inputFile), line, result.err)
 			} else {
-				err = saveSprite(spriteData, frontFilename)
-				if err != nil {
-					fmt.Println("Error saving front sprite:", err)
-				} else {
-					fmt.Println("Front sprite saved as:", frontFilename)
-				}
+				logError("Error fetching %s: %v", result.name, result.err)
+			}
+			emitEvent(progressEvent{Event: "fetch_failed", Name: result.name, Error: result.err.Error()})
+			failed++
+			counts.fetch(false)
+			return
+		}
+		emitEvent(progressEvent{Event: "fetch_done", Name: result.name})
+		counts.fetch(true)
+
+		if len(statNames) > 0 {
+			var missing []string
+			result.pokemon.StatInfo, missing = filterStats(result.pokemon.StatInfo, statNames)
+			for _, name := range missing {
+				logInfo("Warning: %s has no stat %q", result.pokemon.Name, name)
 			}
 		}
 
-		if pokemon.Sprites.BackDefault != "" {
-			backFilename := filepath.Join(".", fmt.Sprintf("%s_back.png", pokemon.Name))
-			spriteData, err := downloadSprite(pokemon.Sprites.BackDefault)
-			if err != nil {
-				fmt.Println("Error downloading back sprite:", err)
+		switch {
+		case *printURLs:
+		case *compact:
+			if err := printCompact(stdout, result.pokemon); err != nil {
+				logError("Error: %v", err)
+				hadErrors = true
+			}
+		case *format == "text":
+			if err := pokeapi.PrintPokemon(stdout, result.pokemon, printOpts); err != nil {
+				logError("Error: %v", err)
+				hadErrors = true
+			}
+			if *chart {
+				printStatChart(stdout, result.pokemon.StatInfo)
+			}
+			if *flavor && !printFlavorText(ctx, client, result.pokemon, *timeout) {
+				hadErrors = true
+			}
+			if *evolution && !printEvolution(ctx, client, result.pokemon, *timeout) {
+				hadErrors = true
+			}
+		case *format == "table":
+			if i > 0 {
+				fmt.Fprintln(stdout)
+			}
+			if err := printTable(stdout, result.pokemon); err != nil {
+				logError("Error: %v", err)
+				hadErrors = true
+			}
+		case *format == "jsonl":
+			var line interface{} = newOutputPokemon(result.pokemon)
+			if flatten {
+				line = flattenPokemon(newOutputPokemon(result.pokemon), *flattenOnly)
+			}
+			if err := printJSONLine(stdout, line); err != nil {
+				logError("Error: %v", err)
+				hadErrors = true
+			}
+		}
+		fetched = append(fetched, result.pokemon)
+		if *noSprites && !*embed && !*printURLs {
+			return
+		}
+		if *artwork && result.pokemon.Sprites.Other.OfficialArtwork.FrontDefault == "" {
+			logInfo("No official artwork available for %s", result.pokemon.Name)
+		}
+		newJobs := spriteJobs(result.pokemon, *outputDir, spriteOpts)
+		if *cry {
+			if job, ok := cryJob(result.pokemon, *outputDir, spriteOpts); ok {
+				newJobs = append(newJobs, job)
 			} else {
-				err = saveSprite(spriteData, backFilename)
-				if err != nil {
-					fmt.Println("Error saving back sprite:", err)
-				} else {
-					fmt.Println("Back sprite saved as:", backFilename)
-				}
+				logInfo("No cry available for %s", result.pokemon.Name)
 			}
 		}
+		for _, job := range newJobs {
+			prefetch(job)
+		}
+		jobs = append(jobs, newJobs...)
+	}
+
+	// JSON lines are written as each fetch completes rather than in input
+	// order, so results can be piped on before the whole batch is done.
+	if *format == "jsonl" {
+		i := 0
+		for result := range fetchStream(ctx, client, names, *timeout, *failFast) {
+			handle(i, result)
+			i++
+		}
+	} else {
+		i := 0
+		for result := range fetchOrdered(ctx, client, names, *timeout, *failFast) {
+			handle(i, result)
+			i++
+		}
+	}
+
+	if *failFast && failed > 0 {
+		if ctx.Err() != nil {
+			exit(exitInterrupted)
+		}
+		exit(1)
+	}
+
+	if *printURLs {
+		if err := printSpriteURLs(stdout, *format, jobs); err != nil {
+			logError("Error: %v", err)
+			exit(1)
+		}
+		if ctx.Err() != nil {
+			exit(exitInterrupted)
+		}
+		if hadErrors || failed > 0 {
+			exit(1)
+		}
+		exit(0)
+	}
+
+	// The dry-run plan and -summary are output the user asked for, so they
+	// go to stdout unless stdout is reserved for JSON or YAML output.
+	var planOut io.Writer = stdout
+	if *format != "text" && *format != "table" {
+		planOut = os.Stderr
+	}
+
+	output := outputAll(fetched)
+	if *embed && len(jobs) > 0 && *dryRun {
+		// Embedding means downloading, which a dry run never does.
+		fmt.Fprintf(planOut, "Would embed %d sprites in the output\n", len(spriteJobsOnly(jobs)))
+	} else if *embed && len(jobs) > 0 {
+		pool.keep = true
+		if embedSprites(ctx, pool, output, jobs, *onDecodeError) > 0 {
+			hadErrors = true
+		}
+	}
+
+	// A single Pokemon is written as an object, a batch as an array.
+	single := len(names) == 1 && len(fetched) == 1
+	var document interface{} = output
+	if single {
+		document = output[0]
+	}
+	if flatten {
+		flat := flattenAll(output, *flattenOnly)
+		document = flat
+		if single {
+			document = flat[0]
+		}
+	}
+
+	if (*format == "json" || *format == "yaml") && len(fetched) > 0 {
+		if err := printStructured(stdout, *format, document); err != nil {
+			logError("Error: %v", err)
+			hadErrors = true
+		}
+	}
+
+	if *summary && len(fetched) > 0 {
+		if err := printSummary(planOut, fetched); err != nil {
+			logError("Error: %v", err)
+			hadErrors = true
+		}
+	}
+
+	if *csvFile != "" && len(fetched) > 0 && *dryRun {
+		fmt.Fprintf(planOut, "Would write stats to %s\n", *csvFile)
+	} else if *csvFile != "" && len(fetched) > 0 {
+		if err := writeStatsCSV(*csvFile, fetched); err != nil {
+			logError("Error: %v", err)
+			hadErrors = true
+		} else {
+			logInfo("Stats written to: %s", *csvFile)
+		}
+	}
+
+	if *metaFile != "" && len(fetched) > 0 && *dryRun {
+		fmt.Fprintf(planOut, "Would write metadata to %s\n", *metaFile)
+	} else if *metaFile != "" && len(fetched) > 0 {
+		if err := writeMetaFile(*metaFile, document); err != nil {
+			logError("Error: %v", err)
+			hadErrors = true
+		} else {
+			logInfo("Metadata written to: %s", *metaFile)
+		}
+	}
+
+	if ctx.Err() != nil {
+		exit(exitInterrupted)
+	}
+
+	if *noSprites {
+		jobs = nil
+	}
+	if err := checkFileNames(jobs); err != nil {
+		logError("Error: %v", err)
+		exit(1)
+	}
+	if !*force {
+		jobs = skipExisting(jobs)
+	}
+
+	if len(jobs) > 0 && *dryRun {
+		printPlan(planOut, jobs)
+	} else if len(jobs) > 0 {
+		if saveSprites(ctx, pool, jobs, spriteOpts, counts) > 0 {
+			hadErrors = true
+		}
+	}
+
+	if len(names) > 1 {
+		// Names collapsed into another one are neither fetched nor failed.
+		if collapsed > 0 {
+			logInfo("Fetched %d of %d Pokemon (%d failed, %d same as another)", len(names)-failed-collapsed, len(names), failed, collapsed)
+		} else {
+			logInfo("Fetched %d of %d Pokemon (%d failed)", len(names)-failed, len(names), failed)
+		}
+		fastest, mean, slowest := fetchTimes(completed)
+		logVerbose("Fetch times: min %v, avg %v, max %v", fastest, mean, slowest)
+	}
+	if *count {
+		if err := counts.print(os.Stderr, *format == "json" || *format == "jsonl"); err != nil {
+			logError("Error: %v", err)
+			hadErrors = true
+		}
+	}
+
+	if ctx.Err() != nil {
+		exit(exitInterrupted)
+	}
+	if hadErrors || failed > 0 {
+		exit(1)
 	}
+	exit(0)
 }
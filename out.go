@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+)
+
+// stdout receives everything gopoke prints as output rather than as a log
+// message. With -o it is a buffer that exit writes to the output file, so the
+// file ends up holding exactly what would have been printed.
+var (
+	stdout  io.Writer = os.Stdout
+	outFile string
+)
+
+// prettyJSON makes printJSON indent its output.
+var prettyJSON = true
+
+// redirectOutput sends all further output to filename instead of stdout.
+func redirectOutput(filename string) {
+	stdout = new(bytes.Buffer)
+	outFile = filename
+}
+
+// exit writes any output redirected with -o and exits with code, or with 1 if
+// the output file couldn't be written.
+func exit(code int) {
+	if buf, ok := stdout.(*bytes.Buffer); ok {
+		if _, err := writeFileAtomic(outFile, buf.Bytes()); err != nil {
+			logError("Error writing output to %s: %v", outFile, err)
+			if code == 0 {
+				code = 1
+			}
+		} else {
+			logInfo("Output written to: %s", outFile)
+		}
+	}
+	os.Exit(code)
+}
@@ -0,0 +1,29 @@
+package main
+
+import "example/start/pokeapi"
+
+// schemaVersion versions the shape of gopoke's own JSON and YAML output, not
+// the upstream API's. Bump it only when a field is renamed or removed;
+// adding fields is not a breaking change.
+const schemaVersion = "1"
+
+// outputPokemon is a Pokemon as gopoke writes it in JSON, JSON lines, YAML
+// and -meta files. SpriteData holds the sprites inlined as data URIs keyed
+// by sprite label, for -embed-sprites.
+type outputPokemon struct {
+	SchemaVersion   string `json:"schema_version" yaml:"schema_version"`
+	pokeapi.Pokemon `yaml:",inline"`
+	SpriteData      map[string]string `json:"sprite_data,omitempty" yaml:"sprite_data,omitempty"`
+}
+
+func newOutputPokemon(p pokeapi.Pokemon) outputPokemon {
+	return outputPokemon{SchemaVersion: schemaVersion, Pokemon: p}
+}
+
+func outputAll(pokemon []pokeapi.Pokemon) []outputPokemon {
+	out := make([]outputPokemon, len(pokemon))
+	for i, p := range pokemon {
+		out[i] = newOutputPokemon(p)
+	}
+	return out
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"example/start/pokeapi"
+)
+
+func TestSchemaVersion(t *testing.T) {
+	data, err := json.Marshal(newOutputPokemon(pokeapi.Pokemon{Name: "pikachu", Id: 25}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), `{"schema_version":"1",`) {
+		t.Errorf("JSON = %s, want it to start with schema_version 1", data)
+	}
+}
@@ -0,0 +1,47 @@
+package pokeapi
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// batchWorkers bounds how many fetches FetchPokemonBatch runs at once.
+const batchWorkers = 4
+
+// FetchPokemonBatch fetches every name concurrently and returns the Pokemon
+// that were found and the errors for those that weren't, both keyed by the
+// name as given. One failure never stops the rest of the batch.
+func (c *Client) FetchPokemonBatch(ctx context.Context, names []string) (map[string]Pokemon, map[string]error) {
+	var mu sync.Mutex
+	found := make(map[string]Pokemon)
+	failed := make(map[string]error)
+
+	var g errgroup.Group
+	g.SetLimit(batchWorkers)
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		name := name
+		g.Go(func() error {
+			pokemon, err := c.GetPokemon(ctx, name)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed[name] = err
+			} else {
+				found[name] = pokemon
+			}
+			return nil
+		})
+	}
+	g.Wait()
+
+	return found, failed
+}
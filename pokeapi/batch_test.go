@@ -0,0 +1,35 @@
+package pokeapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestFetchPokemonBatch(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+
+	names := []string{"pikachu", "missingno", "pikachu", "0", "Pikachu"}
+	found, failed := newTestClient(srv).FetchPokemonBatch(context.Background(), names)
+
+	if len(found) != 2 {
+		t.Errorf("found %d names, want 2: %v", len(found), found)
+	}
+	for _, name := range []string{"pikachu", "Pikachu"} {
+		if p, ok := found[name]; !ok || p.Id != 25 {
+			t.Errorf("found[%q] = %+v, %v, want pikachu #25", name, p, ok)
+		}
+	}
+
+	if len(failed) != 2 {
+		t.Errorf("got %d failures, want 2: %v", len(failed), failed)
+	}
+	var notFound *NotFoundError
+	if err := failed["missingno"]; !errors.As(err, &notFound) {
+		t.Errorf("failed[missingno] = %v, want a NotFoundError", err)
+	}
+	if err := failed["0"]; err == nil || errors.As(err, &notFound) {
+		t.Errorf("failed[0] = %v, want an invalid ID error", err)
+	}
+}
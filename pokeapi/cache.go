@@ -0,0 +1,165 @@
+package pokeapi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const DefaultCacheTTL = 24 * time.Hour
+
+// DefaultCacheDir is $XDG_CACHE_HOME/gopoke, or .cache in the working
+// directory when XDG_CACHE_HOME is unset.
+func DefaultCacheDir() string {
+	if dir := os.Getenv("XDG_CACHE_HOME"); dir != "" {
+		return filepath.Join(dir, "gopoke")
+	}
+	return ".cache"
+}
+
+// Cache stores API responses and downloaded files for a Client. Get reports
+// a miss for anything it can't return. Entries never expire on their own:
+// the Client keeps track of their age itself. Implementations must be safe
+// for concurrent use.
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, data []byte) error
+}
+
+// NopCache is a Cache that stores nothing. A Client with no Cache set uses
+// it.
+type NopCache struct{}
+
+func (NopCache) Get(key string) ([]byte, bool) { return nil, false }
+
+func (NopCache) Set(key string, data []byte) error { return nil }
+
+// DiskCache is a Cache keeping each entry in its own file in a directory,
+// which is created on the first Set.
+type DiskCache struct {
+	dir string
+}
+
+func NewDiskCache(dir string) *DiskCache {
+	return &DiskCache{dir: dir}
+}
+
+func (d *DiskCache) path(key string) string {
+	return filepath.Join(d.dir, url.PathEscape(key))
+}
+
+// Get treats any problem reading the entry as a miss.
+func (d *DiskCache) Get(key string) ([]byte, bool) {
+	data, err := os.ReadFile(d.path(key))
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+func (d *DiskCache) Set(key string, data []byte) error {
+	if err := os.MkdirAll(d.dir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(d.path(key), data, 0644)
+}
+
+func (c *Client) cache() Cache {
+	if c.Cache == nil {
+		return NopCache{}
+	}
+	return c.Cache
+}
+
+func (c *Client) spriteCache() Cache {
+	if c.SpriteCache == nil {
+		return NopCache{}
+	}
+	return c.SpriteCache
+}
+
+// cacheValidators are the ETag and Last-Modified headers a cached response
+// came with, sent back on the next fetch so the server can answer 304 Not
+// Modified instead of resending the body.
+type cacheValidators struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+func (v cacheValidators) empty() bool {
+	return v.ETag == "" && v.LastModified == ""
+}
+
+// cacheMeta is stored next to each cached Pokemon: its validators and when
+// the server last confirmed it, which is what the TTL is measured from.
+type cacheMeta struct {
+	cacheValidators
+	Stored time.Time `json:"stored"`
+}
+
+// fresh reports whether an entry stored at m.Stored is younger than ttl. A
+// ttl of zero or less means entries never expire.
+func (m cacheMeta) fresh(ttl time.Duration) bool {
+	return ttl <= 0 || time.Since(m.Stored) <= ttl
+}
+
+// cacheName is what a Pokemon is cached as: its name followed by a short
+// hash of the API base URL, so clients pointed at different servers never
+// serve each other's responses from a shared cache.
+func cacheName(baseURL, name string) string {
+	sum := sha256.Sum256([]byte(baseURL))
+	return name + "-" + hex.EncodeToString(sum[:4])
+}
+
+// The keys a Pokemon is cached under double as file names in a DiskCache.
+func bodyKey(name string) string { return name + ".json" }
+
+func metaKey(name string) string { return name + ".validators.json" }
+
+// readCache returns the cached body for name, as given by cacheName, with
+// its metadata. An entry
+// stored without metadata, such as one cached by an older version, counts as
+// expired.
+func readCache(cache Cache, name string) ([]byte, cacheMeta, bool) {
+	body, ok := cache.Get(bodyKey(name))
+	if !ok {
+		return nil, cacheMeta{}, false
+	}
+
+	var meta cacheMeta
+	if data, ok := cache.Get(metaKey(name)); ok {
+		if err := json.Unmarshal(data, &meta); err != nil {
+			meta = cacheMeta{}
+		}
+	}
+	return body, meta, true
+}
+
+func writeCache(cache Cache, name string, body []byte, v cacheValidators) error {
+	if err := cache.Set(bodyKey(name), body); err != nil {
+		return err
+	}
+	return touchCache(cache, name, v)
+}
+
+// touchCache records that name's entry, with validators v, was confirmed
+// current just now, restarting its TTL.
+func touchCache(cache Cache, name string, v cacheValidators) error {
+	data, err := json.Marshal(cacheMeta{v, time.Now()})
+	if err != nil {
+		return err
+	}
+	return cache.Set(metaKey(name), data)
+}
+
+// fileCacheKey names a cached download by the SHA-256 of its URL, since
+// sprite and cry URLs are long and full of slashes. Files at a given URL
+// don't change, so these entries never expire.
+func fileCacheKey(fileURL string) string {
+	sum := sha256.Sum256([]byte(fileURL))
+	return hex.EncodeToString(sum[:])
+}
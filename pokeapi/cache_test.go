@@ -0,0 +1,222 @@
+package pokeapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// mapCache is an in-memory Cache that counts its writes.
+type mapCache struct {
+	mu      sync.Mutex
+	entries map[string][]byte
+	sets    int
+}
+
+func newMapCache() *mapCache {
+	return &mapCache{entries: make(map[string][]byte)}
+}
+
+func (m *mapCache) Get(key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.entries[key]
+	return data, ok
+}
+
+func (m *mapCache) Set(key string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[key] = data
+	m.sets++
+	return nil
+}
+
+// store caches body for name, as served from srv, as if the server had
+// confirmed it at stored.
+func (m *mapCache) store(t *testing.T, srv *httptest.Server, name, body, etag string, stored time.Time) {
+	t.Helper()
+	meta, err := json.Marshal(cacheMeta{cacheValidators{ETag: etag}, stored})
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := cacheName(srv.URL, name)
+	m.entries[bodyKey(key)] = []byte(body)
+	m.entries[metaKey(key)] = meta
+}
+
+func (m *mapCache) meta(t *testing.T, srv *httptest.Server, name string) cacheMeta {
+	t.Helper()
+	var meta cacheMeta
+	if err := json.Unmarshal(m.entries[metaKey(cacheName(srv.URL, name))], &meta); err != nil {
+		t.Fatalf("cached metadata for %s: %v", name, err)
+	}
+	return meta
+}
+
+// countingServer serves pikachuJSON with an ETag, answering 304 when the
+// request carries it, and counts the requests it gets.
+func countingServer(hits *int) *httptest.Server {
+	var mu sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		*hits++
+		mu.Unlock()
+
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write([]byte(pikachuJSON))
+	}))
+}
+
+func TestCacheFreshHit(t *testing.T) {
+	var hits int
+	srv := countingServer(&hits)
+	defer srv.Close()
+
+	cache := newMapCache()
+	cache.store(t, srv, "pikachu", pikachuJSON, `"v1"`, time.Now())
+	c := newTestClient(srv)
+	c.Cache = cache
+
+	p, err := c.GetPokemon(context.Background(), "pikachu")
+	if err != nil {
+		t.Fatalf("GetPokemon: %v", err)
+	}
+	if p.Name != "pikachu" {
+		t.Errorf("Name = %q, want pikachu", p.Name)
+	}
+	if hits != 0 {
+		t.Errorf("server got %d requests for a fresh entry, want 0", hits)
+	}
+	if cache.sets != 0 {
+		t.Errorf("cache got %d writes for a fresh entry, want 0", cache.sets)
+	}
+}
+
+func TestCacheExpiredRevalidated(t *testing.T) {
+	var hits int
+	srv := countingServer(&hits)
+	defer srv.Close()
+
+	cache := newMapCache()
+	stored := time.Now().Add(-2 * DefaultCacheTTL)
+	cache.store(t, srv, "pikachu", pikachuJSON, `"v1"`, stored)
+	c := newTestClient(srv)
+	c.Cache = cache
+
+	p, err := c.GetPokemon(context.Background(), "pikachu")
+	if err != nil {
+		t.Fatalf("GetPokemon: %v", err)
+	}
+	if p.Name != "pikachu" {
+		t.Errorf("Name = %q, want pikachu", p.Name)
+	}
+	if hits != 1 {
+		t.Errorf("server got %d requests, want 1", hits)
+	}
+
+	meta := cache.meta(t, srv, "pikachu")
+	if !meta.Stored.After(stored) || !meta.fresh(DefaultCacheTTL) {
+		t.Errorf("entry stored at %v after a 304, want it refreshed", meta.Stored)
+	}
+	if meta.ETag != `"v1"` {
+		t.Errorf("ETag = %q after a 304, want it kept", meta.ETag)
+	}
+}
+
+func TestCacheUnreadableEntryRefetched(t *testing.T) {
+	var hits int
+	srv := countingServer(&hits)
+	defer srv.Close()
+
+	cache := newMapCache()
+	cache.store(t, srv, "pikachu", "not json", "", time.Now())
+	c := newTestClient(srv)
+	c.Cache = cache
+
+	p, err := c.GetPokemon(context.Background(), "pikachu")
+	if err != nil {
+		t.Fatalf("GetPokemon: %v", err)
+	}
+	if p.Name != "pikachu" {
+		t.Errorf("Name = %q, want pikachu", p.Name)
+	}
+	if hits != 1 {
+		t.Errorf("server got %d requests, want 1", hits)
+	}
+	if got := string(cache.entries[bodyKey(cacheName(srv.URL, "pikachu"))]); got != pikachuJSON {
+		t.Errorf("cached body = %q, want the refetched response", got)
+	}
+	if meta := cache.meta(t, srv, "pikachu"); meta.ETag != `"v1"` {
+		t.Errorf("cached ETag = %q, want the refetched response's", meta.ETag)
+	}
+}
+
+func TestCacheKeyedByBaseURL(t *testing.T) {
+	var hits int
+	srv := countingServer(&hits)
+	defer srv.Close()
+	other := httptest.NewServer(http.NotFoundHandler())
+	defer other.Close()
+
+	cache := newMapCache()
+	cache.store(t, other, "pikachu", "not this one", "", time.Now())
+	c := newTestClient(srv)
+	c.Cache = cache
+
+	p, err := c.GetPokemon(context.Background(), "pikachu")
+	if err != nil {
+		t.Fatalf("GetPokemon: %v", err)
+	}
+	if p.Name != "pikachu" {
+		t.Errorf("Name = %q, want pikachu", p.Name)
+	}
+	if hits != 1 {
+		t.Errorf("server got %d requests with only another server's entry cached, want 1", hits)
+	}
+}
+
+func TestCacheReadOnly(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		if r.URL.Path == "/cry.ogg" {
+			w.Write([]byte("OggS"))
+			return
+		}
+		w.Write([]byte(pikachuJSON))
+	}))
+	defer srv.Close()
+
+	cache := newMapCache()
+	cache.store(t, srv, "raichu", pikachuJSON, `"v1"`, time.Now().Add(-2*DefaultCacheTTL))
+	c := newTestClient(srv)
+	c.Cache = cache
+	c.SpriteCache = cache
+	c.CacheReadOnly = true
+
+	ctx := context.Background()
+	if _, err := c.GetPokemon(ctx, "pikachu"); err != nil {
+		t.Fatalf("GetPokemon: %v", err)
+	}
+	if _, err := c.GetPokemon(ctx, "raichu"); err != nil {
+		t.Fatalf("GetPokemon of an expired entry: %v", err)
+	}
+	if _, err := c.DownloadFile(ctx, srv.URL+"/cry.ogg"); err != nil {
+		t.Fatalf("DownloadFile: %v", err)
+	}
+	if cache.sets != 0 {
+		t.Errorf("read-only cache got %d writes, want 0", cache.sets)
+	}
+}
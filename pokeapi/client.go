@@ -0,0 +1,680 @@
+// Package pokeapi fetches Pokemon data and sprites from PokeAPI, or any
+// server that speaks the same JSON API.
+package pokeapi
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptrace"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	DefaultBaseURL   = "https://pokeapi-proxy.freecodecamp.rocks/api"
+	DefaultUserAgent = "gopoke/1.0"
+	DefaultRetries   = 3
+
+	// SpriteHost is where PokeAPI serves its sprites from.
+	SpriteHost = "raw.githubusercontent.com"
+
+	// DefaultMaxBodySize and DefaultMaxSpriteSize cap how much of an API
+	// response or a sprite is read before giving up.
+	DefaultMaxBodySize   = 8 << 20
+	DefaultMaxSpriteSize = 32 << 20
+
+	// DefaultMaxRedirects matches the limit net/http applies by default.
+	DefaultMaxRedirects = 10
+
+	retryBaseDelay = 500 * time.Millisecond
+)
+
+// Client fetches Pokemon data and sprites from a PokeAPI-compatible server.
+// Cache, when set, keeps Pokemon responses, for example on disk with a
+// DiskCache; entries older than CacheTTL are revalidated with If-None-Match
+// or If-Modified-Since when the server sent an ETag or Last-Modified for
+// them. SpriteCache keeps downloaded sprites and other files, which never
+// expire. CacheReadOnly serves from both without ever writing new entries.
+// Progress, when set, receives a running download indicator meant for a
+// terminal.
+// Header holds extra headers added to every request, API fetches and file
+// downloads alike; a User-Agent set there overrides UserAgent.
+// Offline makes every request fail with an OfflineError, so only cached
+// data is served; cached Pokemon are then used however old they are.
+// Limiter, when set, throttles every outgoing request including retries, and
+// Jitter delays each one by a random amount up to that long so concurrent
+// workers don't all fire at once.
+// MaxBodySize and MaxSpriteSize bound the bytes read from a single API
+// response or downloaded file; zero or less means no limit. SpriteHosts, when
+// non-empty, is the only set of hosts DownloadSprite and DownloadFile will
+// contact.
+// MaxRedirects caps how many redirects a request follows before failing with
+// a RedirectError; zero follows none. It applies unless HTTPClient has a
+// CheckRedirect of its own.
+//
+// Logf receives notices such as retries and cache write failures, and Debugf
+// the per-request URLs and timings. Both are optional; a Client is silent by
+// default.
+type Client struct {
+	HTTPClient    *http.Client
+	BaseURL       string
+	Retries       int
+	Cache         Cache
+	SpriteCache   Cache
+	CacheTTL      time.Duration
+	CacheReadOnly bool
+	Offline       bool
+	Progress      io.Writer
+	UserAgent     string
+	Header        http.Header
+	Limiter       *rate.Limiter
+	Jitter        time.Duration
+	MaxBodySize   int64
+	MaxSpriteSize int64
+	SpriteHosts   []string
+	MaxRedirects  int
+	Logf          func(format string, args ...interface{})
+	Debugf        func(format string, args ...interface{})
+
+	memCache *lruCache
+}
+
+// DefaultClient is the Client used by FetchPokemon, DownloadSprite and
+// DownloadFile.
+var DefaultClient = NewClient(DefaultBaseURL)
+
+// FetchPokemon fetches a Pokemon by name or Pokedex ID using DefaultClient.
+func FetchPokemon(ctx context.Context, name string) (Pokemon, error) {
+	return DefaultClient.GetPokemon(ctx, name)
+}
+
+// DownloadSprite downloads a sprite image using DefaultClient.
+func DownloadSprite(ctx context.Context, url string) ([]byte, error) {
+	return DefaultClient.DownloadSprite(ctx, url)
+}
+
+// DownloadFile downloads any file the API links to using DefaultClient.
+func DownloadFile(ctx context.Context, url string) ([]byte, error) {
+	return DefaultClient.DownloadFile(ctx, url)
+}
+
+// NormalizeName trims and lowercases a Pokemon name or ID the way the API
+// expects it in a URL, dropping leading zeros from IDs so "025" and "25"
+// are the same.
+func NormalizeName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if id, err := strconv.Atoi(name); err == nil && id >= 0 {
+		return strconv.Itoa(id)
+	}
+	return name
+}
+
+// errBodyTooLarge is returned by readLimited when the body goes past the limit.
+var errBodyTooLarge = errors.New("body too large")
+
+// MaxPokemonID is the largest numeric ID ValidateID accepts. PokeAPI's own IDs,
+// alternate forms included, stay well below it.
+const MaxPokemonID = 99999
+
+// ValidateID reports an error if a normalized name looks like a Pokedex ID
+// but is out of range, or contains a character that would change the request
+// URL's path or end it early. Anything else that isn't all digits,
+// optionally signed, is treated as a name and accepted.
+func ValidateID(name string) error {
+	if strings.ContainsAny(name, "/?#") {
+		return fmt.Errorf("invalid Pokemon name %q: must not contain /, ? or #", name)
+	}
+
+	digits := strings.TrimPrefix(strings.TrimPrefix(name, "-"), "+")
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return nil
+	}
+
+	id, err := strconv.Atoi(name)
+	if err != nil || id < 1 || id > MaxPokemonID {
+		return fmt.Errorf("invalid Pokedex ID %s: must be between 1 and %d", name, MaxPokemonID)
+	}
+	return nil
+}
+
+func (c *Client) logf(format string, args ...interface{}) {
+	if c.Logf != nil {
+		c.Logf(format, args...)
+	}
+}
+
+func (c *Client) debugf(format string, args ...interface{}) {
+	if c.Debugf != nil {
+		c.Debugf(format, args...)
+	}
+}
+
+// Option configures optional Client behaviour in NewClient.
+type Option func(*Client)
+
+// WithCache keeps up to size parsed Pokemon in memory so repeated
+// GetPokemon calls for the same name skip the network entirely.
+func WithCache(size int) Option {
+	return func(c *Client) {
+		if size > 0 {
+			c.memCache = newLRUCache(size)
+		}
+	}
+}
+
+// WithHTTPClient sends every request through hc instead of
+// http.DefaultClient.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		if hc != nil {
+			c.HTTPClient = hc
+		}
+	}
+}
+
+// WithTransport sends every request through rt, for example to mock the
+// server or add authentication.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) {
+		c.HTTPClient = &http.Client{Transport: rt}
+	}
+}
+
+func NewClient(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		HTTPClient:    http.DefaultClient,
+		BaseURL:       baseURL,
+		Retries:       DefaultRetries,
+		CacheTTL:      DefaultCacheTTL,
+		UserAgent:     DefaultUserAgent,
+		MaxBodySize:   DefaultMaxBodySize,
+		MaxSpriteSize: DefaultMaxSpriteSize,
+		MaxRedirects:  DefaultMaxRedirects,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// ClearCache empties the in-memory cache enabled by WithCache.
+func (c *Client) ClearCache() {
+	if c.memCache != nil {
+		c.memCache.Clear()
+	}
+}
+
+func (c *Client) GetPokemon(ctx context.Context, name string) (Pokemon, error) {
+	name = NormalizeName(name)
+	if err := ValidateID(name); err != nil {
+		return Pokemon{}, err
+	}
+
+	if c.memCache != nil {
+		if pokemon, ok := c.memCache.Get(name); ok {
+			return pokemon, nil
+		}
+	}
+
+	pokemon, err := c.getPokemon(ctx, name)
+	if err != nil {
+		return Pokemon{}, err
+	}
+
+	if c.memCache != nil {
+		c.memCache.Add(name, pokemon)
+	}
+	return pokemon, nil
+}
+
+func (c *Client) getPokemon(ctx context.Context, name string) (Pokemon, error) {
+	// A cached body that no longer parses is ignored and refetched. An
+	// expired entry whose response carried an ETag or Last-Modified is
+	// revalidated rather than refetched; without validators the TTL alone
+	// decides.
+	var stale []byte
+	var validators cacheValidators
+	key := cacheName(c.BaseURL, name)
+	if body, meta, ok := readCache(c.cache(), key); ok {
+		pokemon, err := ParsePokemon(body)
+		switch {
+		case err != nil || pokemon.Validate() != nil:
+			c.debugf("Ignoring unreadable cache entry for %s", name)
+		case meta.fresh(c.CacheTTL):
+			c.debugf("Using cached data for %s", name)
+			return pokemon, nil
+		default:
+			stale = body
+			validators = meta.cacheValidators
+		}
+	}
+
+	url := fmt.Sprintf("%s/pokemon/%s/", c.BaseURL, name)
+	if c.Offline && stale != nil {
+		c.debugf("Using expired cached data for %s while offline", name)
+		return ParsePokemon(stale)
+	}
+	body, fresh, err := c.fetchDataIf(ctx, url, validators)
+	if errors.Is(err, errNotModified) {
+		c.debugf("Cached data for %s is still current", name)
+		if !c.CacheReadOnly {
+			if err := touchCache(c.cache(), key, validators); err != nil {
+				c.logf("Warning: could not refresh cache entry for %s: %v", name, err)
+			}
+		}
+		return ParsePokemon(stale)
+	}
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) && statusErr.Code == http.StatusNotFound {
+		return Pokemon{}, &NotFoundError{Name: name}
+	}
+	if err != nil {
+		return Pokemon{}, err
+	}
+
+	pokemon, err := ParsePokemon(body)
+	if err != nil {
+		return Pokemon{}, err
+	}
+	if err := pokemon.Validate(); err != nil {
+		return Pokemon{}, err
+	}
+
+	if !c.CacheReadOnly {
+		if err := writeCache(c.cache(), key, body, fresh); err != nil {
+			c.logf("Warning: could not cache %s: %v", name, err)
+		}
+	}
+
+	return pokemon, nil
+}
+
+// checkHost refuses download URLs whose host isn't in SpriteHosts.
+func (c *Client) checkHost(fileURL string) error {
+	if len(c.SpriteHosts) == 0 {
+		return nil
+	}
+
+	u, err := neturl.Parse(fileURL)
+	if err != nil {
+		return fmt.Errorf("invalid download URL %q: %v", fileURL, err)
+	}
+	for _, host := range c.SpriteHosts {
+		if strings.EqualFold(u.Hostname(), host) {
+			return nil
+		}
+	}
+	return fmt.Errorf("refusing to download from %s: host is not allowed", u.Hostname())
+}
+
+// DownloadSprite downloads a sprite image, rejecting anything that isn't a
+// readable image.
+func (c *Client) DownloadSprite(ctx context.Context, url string) ([]byte, error) {
+	return c.downloadFile(ctx, url, ValidateSprite)
+}
+
+// DownloadFile downloads any other file the API links to, such as a cry,
+// with the same retries, size limit and cache as DownloadSprite.
+func (c *Client) DownloadFile(ctx context.Context, url string) ([]byte, error) {
+	return c.downloadFile(ctx, url, nil)
+}
+
+// downloadFile is the GET behind every sprite and file download. Downloaded
+// and cached data are checked with validate when it is non-nil.
+func (c *Client) downloadFile(ctx context.Context, url string, validate func(string, []byte) error) ([]byte, error) {
+	if err := c.checkHost(url); err != nil {
+		return nil, err
+	}
+
+	// A cached file that no longer validates is ignored and downloaded again.
+	if data, ok := c.spriteCache().Get(fileCacheKey(url)); ok {
+		if validate == nil || validate(url, data) == nil {
+			c.debugf("Using cached copy of %s", url)
+			return data, nil
+		}
+		c.debugf("Ignoring unreadable cached copy of %s", url)
+	}
+	if c.Offline {
+		return nil, &OfflineError{URL: url}
+	}
+
+	c.debugf("Downloading %s", url)
+	start := time.Now()
+
+	var data []byte
+	err := c.withRetry(ctx, func() (bool, error) {
+		var retryable bool
+		var err error
+		data, retryable, err = c.downloadFileOnce(ctx, url)
+		return retryable, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if validate != nil {
+		if err := validate(url, data); err != nil {
+			return nil, fmt.Errorf("sprite %s: %v", url, err)
+		}
+	}
+	c.debugf("Downloaded %s in %v (%d bytes)", url, time.Since(start), len(data))
+
+	if !c.CacheReadOnly {
+		if err := c.spriteCache().Set(fileCacheKey(url), data); err != nil {
+			c.logf("Warning: could not cache %s: %v", url, err)
+		}
+	}
+
+	return data, nil
+}
+
+func (c *Client) fetchData(ctx context.Context, url string) ([]byte, error) {
+	body, _, err := c.fetchDataIf(ctx, url, cacheValidators{})
+	return body, err
+}
+
+// errNotModified is returned by fetchDataIf when the server answers 304 to a
+// conditional request.
+var errNotModified = errors.New("not modified")
+
+// fetchDataIf fetches url, making the request conditional on cond when it is
+// non-empty, and returns the body along with the response's own validators.
+func (c *Client) fetchDataIf(ctx context.Context, url string, cond cacheValidators) ([]byte, cacheValidators, error) {
+	if c.Offline {
+		return nil, cacheValidators{}, &OfflineError{URL: url}
+	}
+	c.debugf("Fetching %s", url)
+	start := time.Now()
+
+	var body []byte
+	var validators cacheValidators
+	err := c.withRetry(ctx, func() (bool, error) {
+		var retryable bool
+		var err error
+		body, validators, retryable, err = c.fetchOnce(ctx, url, cond)
+		return retryable, err
+	})
+	if err != nil {
+		return nil, cacheValidators{}, err
+	}
+	c.debugf("Fetched %s in %v (%d bytes)", url, time.Since(start), len(body))
+
+	return body, validators, nil
+}
+
+func deadlineExceeded(ctx context.Context) bool {
+	return ctx.Err() == context.DeadlineExceeded
+}
+
+// parseRetryAfter reads a Retry-After header given either in seconds or as an
+// HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func statusError(resp *http.Response, url string) *HTTPStatusError {
+	err := &HTTPStatusError{Code: resp.StatusCode, URL: url}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		err.RetryAfter, _ = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	}
+	return err
+}
+
+// retryableStatus reports whether a request that got code is worth repeating.
+func retryableStatus(code int) bool {
+	return code >= 500 || code == http.StatusTooManyRequests
+}
+
+// withRetry calls attempt until it succeeds, returns an error it doesn't
+// mark retryable, or has been retried c.Retries times. Network errors, 5xx
+// responses and 429 Too Many Requests are retried with exponential backoff,
+// except that a response carrying a Retry-After header waits as long as it
+// asks instead.
+func (c *Client) withRetry(ctx context.Context, attempt func() (bool, error)) error {
+	delay := retryBaseDelay
+	for n := 1; ; n++ {
+		retryable, err := attempt()
+		if err == nil {
+			return nil
+		}
+		if !retryable || n > c.Retries || ctx.Err() != nil {
+			if n > 1 {
+				return fmt.Errorf("%w (after %d attempts)", err, n)
+			}
+			return err
+		}
+
+		// A server that says how long to wait is obeyed instead of the
+		// backoff, unless that would run past the deadline anyway.
+		wait := delay
+		var statusErr *HTTPStatusError
+		if errors.As(err, &statusErr) && statusErr.RetryAfter > 0 {
+			wait = statusErr.RetryAfter
+			if deadline, ok := ctx.Deadline(); ok && time.Now().Add(wait).After(deadline) {
+				return fmt.Errorf("%w (server asked to retry after %v, past the deadline)", err, wait)
+			}
+		}
+
+		c.logf("Attempt %d of %d failed: %v; retrying in %v", n, c.Retries+1, err, wait)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return fmt.Errorf("%w (after %d attempts)", err, n)
+		}
+		delay *= 2
+	}
+}
+
+// do sends req through HTTPClient, following redirects up to MaxRedirects.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	hc := *c.HTTPClient
+	if hc.CheckRedirect == nil {
+		hc.CheckRedirect = c.checkRedirect
+	}
+	return hc.Do(req)
+}
+
+func (c *Client) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) > c.MaxRedirects {
+		return &RedirectError{URL: req.URL.String(), Max: c.MaxRedirects}
+	}
+	c.debugf("Redirect %d for %s: %s", len(via), via[0].URL, req.URL)
+	return nil
+}
+
+// isRedirectError reports whether err came from exceeding MaxRedirects, which
+// retrying won't fix.
+func isRedirectError(err error) bool {
+	var redirectErr *RedirectError
+	return errors.As(err, &redirectErr)
+}
+
+// newRequest builds a GET request carrying the headers every outgoing request
+// shares, so API fetches and sprite downloads look the same to the server.
+// The returned timing fills in as the request runs.
+func (c *Client) newRequest(ctx context.Context, url string) (*http.Request, *requestTiming, error) {
+	timing := &requestTiming{}
+	ctx = httptrace.WithClientTrace(ctx, timing.trace())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	for key, values := range c.Header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	if c.UserAgent != "" && req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+	return req, timing, nil
+}
+
+// readLimited reads r to the end, failing once more than limit bytes have
+// been read. A limit of zero or less reads everything.
+func readLimited(r io.Reader, limit int64) ([]byte, error) {
+	if limit <= 0 {
+		return io.ReadAll(r)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, errBodyTooLarge
+	}
+	return data, nil
+}
+
+// wait sleeps for a random part of Jitter and then until Limiter allows
+// another request, giving up early if ctx is done.
+func (c *Client) wait(ctx context.Context) error {
+	if c.Jitter > 0 {
+		select {
+		case <-time.After(time.Duration(rand.Int63n(int64(c.Jitter)))):
+		case <-ctx.Done():
+			return fmt.Errorf("waiting before request: %v", ctx.Err())
+		}
+	}
+
+	if c.Limiter == nil {
+		return nil
+	}
+	if err := c.Limiter.Wait(ctx); err != nil {
+		return fmt.Errorf("waiting for rate limiter: %v", err)
+	}
+	return nil
+}
+
+func (c *Client) fetchOnce(ctx context.Context, url string, cond cacheValidators) ([]byte, cacheValidators, bool, error) {
+	none := cacheValidators{}
+	req, timing, err := c.newRequest(ctx, url)
+	if err != nil {
+		return nil, none, false, fmt.Errorf("error creating request: %v", err)
+	}
+	// Setting Accept-Encoding by hand turns off the transport's transparent
+	// decompression, so gzip bodies are decoded below.
+	req.Header.Set("Accept-Encoding", "gzip")
+	if cond.ETag != "" {
+		req.Header.Set("If-None-Match", cond.ETag)
+	}
+	if cond.LastModified != "" {
+		req.Header.Set("If-Modified-Since", cond.LastModified)
+	}
+	if err := c.wait(ctx); err != nil {
+		return nil, none, false, err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		if deadlineExceeded(ctx) {
+			return nil, none, false, fmt.Errorf("request to %s exceeded the deadline", url)
+		}
+		return nil, none, !isRedirectError(err), fmt.Errorf("HTTP request error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && !cond.empty() {
+		return nil, none, false, errNotModified
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, none, retryableStatus(resp.StatusCode), statusError(resp, url)
+	}
+
+	var reader io.Reader = resp.Body
+	if strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, none, true, fmt.Errorf("error decompressing response body: %v", err)
+		}
+		defer gz.Close()
+		reader = gz
+	}
+
+	body, err := readLimited(reader, c.MaxBodySize)
+	if errors.Is(err, errBodyTooLarge) {
+		return nil, none, false, fmt.Errorf("response from %s is larger than %d bytes", url, c.MaxBodySize)
+	}
+	if err != nil {
+		if deadlineExceeded(ctx) {
+			return nil, none, false, fmt.Errorf("request to %s exceeded the deadline while reading the body", url)
+		}
+		return nil, none, true, fmt.Errorf("error reading response body: %v", err)
+	}
+
+	c.debugf("Timing for %s: %s", url, timing.breakdown(time.Now()))
+
+	validators := cacheValidators{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+	return body, validators, false, nil
+}
+
+func (c *Client) downloadFileOnce(ctx context.Context, url string) ([]byte, bool, error) {
+	req, timing, err := c.newRequest(ctx, url)
+	if err != nil {
+		return nil, false, fmt.Errorf("error creating request: %v", err)
+	}
+	if err := c.wait(ctx); err != nil {
+		return nil, false, err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		if deadlineExceeded(ctx) {
+			return nil, false, fmt.Errorf("download of %s exceeded the deadline", url)
+		}
+		return nil, !isRedirectError(err), fmt.Errorf("error downloading %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, retryableStatus(resp.StatusCode), statusError(resp, url)
+	}
+
+	var body io.Reader = resp.Body
+	if c.Progress != nil {
+		progress := newProgressReader(c.Progress, resp.Body, url, resp.ContentLength)
+		defer progress.Done()
+		body = progress
+	}
+
+	data, err := readLimited(body, c.MaxSpriteSize)
+	if errors.Is(err, errBodyTooLarge) {
+		return nil, false, fmt.Errorf("%s is larger than %d bytes", url, c.MaxSpriteSize)
+	}
+	if err != nil {
+		if deadlineExceeded(ctx) {
+			return nil, false, fmt.Errorf("download of %s exceeded the deadline while reading the body", url)
+		}
+		return nil, true, fmt.Errorf("error reading %s: %v", url, err)
+	}
+
+	c.debugf("Timing for %s: %s", url, timing.breakdown(time.Now()))
+
+	return data, false, nil
+}
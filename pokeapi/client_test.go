@@ -0,0 +1,230 @@
+package pokeapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// pikachuJSON is a trimmed /pokemon response with every field the tests
+// look at.
+const pikachuJSON = `{
+	"name": "pikachu",
+	"id": 25,
+	"base_experience": 112,
+	"height": 4,
+	"weight": 60,
+	"sprites": {
+		"front_default": "https://example.com/sprites/25.png",
+		"back_default": "https://example.com/sprites/back/25.png",
+		"front_shiny": null,
+		"other": {"official-artwork": {"front_default": "https://example.com/artwork/25.png"}}
+	},
+	"stats": [
+		{"base_stat": 35, "stat": {"name": "hp"}},
+		{"base_stat": 55, "stat": {"name": "attack"}},
+		{"base_stat": 40, "stat": {"name": "defense"}},
+		{"base_stat": 50, "stat": {"name": "special-attack"}},
+		{"base_stat": 50, "stat": {"name": "special-defense"}},
+		{"base_stat": 90, "stat": {"name": "speed"}}
+	],
+	"types": [{"slot": 1, "type": {"name": "electric"}}]
+}`
+
+// newTestServer serves pikachuJSON for /pokemon/pikachu/ and 404 for any
+// other path.
+func newTestServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/pokemon/pikachu/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(pikachuJSON))
+	}))
+}
+
+// newTestClient returns a Client for srv that never retries, so error paths
+// fail straight away.
+func newTestClient(srv *httptest.Server) *Client {
+	c := NewClient(srv.URL)
+	c.Retries = 0
+	return c
+}
+
+func TestFetchData(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+
+	body, err := newTestClient(srv).fetchData(context.Background(), srv.URL+"/pokemon/pikachu/")
+	if err != nil {
+		t.Fatalf("fetchData: %v", err)
+	}
+	if string(body) != pikachuJSON {
+		t.Errorf("fetchData body = %q, want the served JSON", body)
+	}
+}
+
+func TestParsePokemon(t *testing.T) {
+	p, err := ParsePokemon([]byte(pikachuJSON))
+	if err != nil {
+		t.Fatalf("ParsePokemon: %v", err)
+	}
+
+	if p.Name != "pikachu" {
+		t.Errorf("Name = %q, want pikachu", p.Name)
+	}
+	if p.Id != 25 {
+		t.Errorf("Id = %d, want 25", p.Id)
+	}
+	if p.Sprites.FrontDefault != "https://example.com/sprites/25.png" {
+		t.Errorf("Sprites.FrontDefault = %q", p.Sprites.FrontDefault)
+	}
+	if p.Sprites.BackDefault != "https://example.com/sprites/back/25.png" {
+		t.Errorf("Sprites.BackDefault = %q", p.Sprites.BackDefault)
+	}
+	if p.Sprites.Other.OfficialArtwork.FrontDefault != "https://example.com/artwork/25.png" {
+		t.Errorf("official artwork = %q", p.Sprites.Other.OfficialArtwork.FrontDefault)
+	}
+	if len(p.StatInfo) != 6 {
+		t.Fatalf("len(StatInfo) = %d, want 6", len(p.StatInfo))
+	}
+	if got := p.StatInfo[0]; got.Stat.Name != "hp" || got.BaseStat != 35 {
+		t.Errorf("StatInfo[0] = %+v, want hp 35", got)
+	}
+}
+
+func TestParsePokemonMalformed(t *testing.T) {
+	if _, err := ParsePokemon([]byte(`{"name": "pikachu",`)); err == nil {
+		t.Error("ParsePokemon accepted truncated JSON")
+	}
+}
+
+func TestGetPokemon(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+
+	p, err := newTestClient(srv).GetPokemon(context.Background(), "Pikachu")
+	if err != nil {
+		t.Fatalf("GetPokemon: %v", err)
+	}
+	if p.Name != "pikachu" || p.Id != 25 {
+		t.Errorf("GetPokemon = %s #%d, want pikachu #25", p.Name, p.Id)
+	}
+}
+
+func TestGetPokemonStatusError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	_, err := newTestClient(srv).GetPokemon(context.Background(), "pikachu")
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("GetPokemon error = %v, want an HTTPStatusError", err)
+	}
+	if statusErr.Code != http.StatusInternalServerError {
+		t.Errorf("Code = %d, want 500", statusErr.Code)
+	}
+}
+
+func TestGetPokemonMalformedJSON(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name": "pikachu", "stats": [`))
+	}))
+	defer srv.Close()
+
+	_, err := newTestClient(srv).GetPokemon(context.Background(), "pikachu")
+	if err == nil || !strings.Contains(err.Error(), "error parsing JSON") {
+		t.Errorf("GetPokemon error = %v, want a JSON parsing error", err)
+	}
+}
+
+func TestValidateID(t *testing.T) {
+	for _, name := range []string{"pikachu", "25", "mr-mime", "99999"} {
+		if err := ValidateID(name); err != nil {
+			t.Errorf("ValidateID(%q) = %v, want nil", name, err)
+		}
+	}
+	for _, name := range []string{"0", "-1", "100000", "../berry", "pikachu?x=1", "pikachu#frag", "a/b"} {
+		if err := ValidateID(name); err == nil {
+			t.Errorf("ValidateID(%q) = nil, want an error", name)
+		}
+	}
+}
+
+func TestGetPokemonNotFound(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+
+	_, err := newTestClient(srv).GetPokemon(context.Background(), "pikachoo")
+	var notFound *NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("GetPokemon error = %v, want a NotFoundError", err)
+	}
+	if notFound.Name != "pikachoo" {
+		t.Errorf("NotFoundError.Name = %q, want pikachoo", notFound.Name)
+	}
+	if want := `pokemon "pikachoo" not found`; err.Error() != want {
+		t.Errorf("error = %q, want %q", err, want)
+	}
+}
+
+// stubTransport answers every request from canned bodies keyed by URL, with
+// 404 for anything else, without touching the network.
+type stubTransport map[string][]byte
+
+func (s stubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     make(http.Header),
+		Request:    req,
+	}
+	body, ok := s[req.URL.String()]
+	if !ok {
+		resp.StatusCode = http.StatusNotFound
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+func TestWithTransport(t *testing.T) {
+	var sprite bytes.Buffer
+	if err := png.Encode(&sprite, image.NewRGBA(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatal(err)
+	}
+	stub := stubTransport{
+		"http://pokeapi.invalid/api/pokemon/pikachu/": []byte(pikachuJSON),
+		"https://example.com/sprites/25.png":          sprite.Bytes(),
+	}
+	c := NewClient("http://pokeapi.invalid/api", WithTransport(stub))
+	c.Retries = 0
+	ctx := context.Background()
+
+	p, err := c.GetPokemon(ctx, "pikachu")
+	if err != nil {
+		t.Fatalf("GetPokemon: %v", err)
+	}
+	if p.Name != "pikachu" {
+		t.Errorf("Name = %q, want pikachu", p.Name)
+	}
+
+	data, err := c.DownloadSprite(ctx, p.Sprites.FrontDefault)
+	if err != nil {
+		t.Fatalf("DownloadSprite: %v", err)
+	}
+	if !bytes.Equal(data, sprite.Bytes()) {
+		t.Errorf("DownloadSprite returned %d bytes, want the %d stubbed", len(data), sprite.Len())
+	}
+
+	if _, err := c.DownloadSprite(ctx, p.Sprites.BackDefault); err == nil {
+		t.Error("DownloadSprite of an unstubbed URL succeeded")
+	}
+}
@@ -0,0 +1,53 @@
+package pokeapi
+
+import (
+	"fmt"
+	"time"
+)
+
+// HTTPStatusError is returned when a server answers with anything other than
+// 200 OK, after any retries. RetryAfter is the delay the server asked for
+// with a Retry-After header, if any.
+type HTTPStatusError struct {
+	Code       int
+	URL        string
+	RetryAfter time.Duration
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", e.Code)
+}
+
+// NotFoundError is returned by GetPokemon when no Pokemon has the requested
+// name or ID.
+type NotFoundError struct {
+	Name string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("pokemon %q not found", e.Name)
+}
+
+// RedirectError is returned when a request is redirected more than
+// Client.MaxRedirects times. URL is where the last redirect pointed.
+type RedirectError struct {
+	URL string
+	Max int
+}
+
+func (e *RedirectError) Error() string {
+	if e.Max == 0 {
+		return fmt.Sprintf("redirected to %s, but following redirects is disabled", e.URL)
+	}
+	return fmt.Sprintf("stopped after %d redirects, the last to %s", e.Max, e.URL)
+}
+
+// OfflineError is returned instead of making a request when Client.Offline
+// is set and the data isn't in the cache.
+type OfflineError struct {
+	URL string
+}
+
+func (e *OfflineError) Error() string {
+	return fmt.Sprintf("%s is not cached and the client is offline", e.URL)
+}
@@ -0,0 +1,64 @@
+package pokeapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+type EvolutionChainLink struct {
+	URL string `json:"url" yaml:"url"`
+}
+
+// ChainLink is one species in an evolution chain together with everything
+// it can evolve into. A species with several entries in EvolvesTo, such as
+// Eevee, branches.
+type ChainLink struct {
+	Species   Species     `json:"species" yaml:"species"`
+	EvolvesTo []ChainLink `json:"evolves_to" yaml:"evolves_to"`
+}
+
+type EvolutionChain struct {
+	Id    int32     `json:"id" yaml:"id"`
+	Chain ChainLink `json:"chain" yaml:"chain"`
+}
+
+// Lines returns every evolutionary line in the chain as the species names
+// from the first stage to a final one, one line per branch.
+func (e EvolutionChain) Lines() [][]string {
+	var lines [][]string
+	collectLines(e.Chain, nil, &lines)
+	return lines
+}
+
+func collectLines(link ChainLink, prefix []string, lines *[][]string) {
+	line := append(prefix[:len(prefix):len(prefix)], link.Species.Name)
+	if len(link.EvolvesTo) == 0 {
+		*lines = append(*lines, line)
+		return
+	}
+	for _, next := range link.EvolvesTo {
+		collectLines(next, line, lines)
+	}
+}
+
+// GetEvolutionChain follows the species' evolution chain link.
+func (c *Client) GetEvolutionChain(ctx context.Context, species SpeciesInfo) (EvolutionChain, error) {
+	url := species.EvolutionChain.URL
+	if url == "" {
+		return EvolutionChain{}, errors.New("species has no evolution chain")
+	}
+
+	body, err := c.fetchData(ctx, url)
+	if err != nil {
+		return EvolutionChain{}, err
+	}
+
+	var chain EvolutionChain
+	if err := json.Unmarshal(body, &chain); err != nil {
+		return EvolutionChain{}, fmt.Errorf("error parsing evolution chain JSON: %v", err)
+	}
+
+	return chain, nil
+}
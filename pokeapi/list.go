@@ -0,0 +1,76 @@
+package pokeapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type PokemonListEntry struct {
+	Name string `json:"name" yaml:"name"`
+	URL  string `json:"url" yaml:"url"`
+}
+
+// ID extracts the Pokedex ID from the entry's resource URL, returning 0 if
+// the URL does not end in a numeric path segment.
+func (e PokemonListEntry) ID() int {
+	id, err := strconv.Atoi(path.Base(strings.TrimRight(e.URL, "/")))
+	if err != nil {
+		return 0
+	}
+	return id
+}
+
+type PokemonList struct {
+	Count   int                `json:"count" yaml:"count"`
+	Results []PokemonListEntry `json:"results" yaml:"results"`
+}
+
+// ListPokemon fetches one page of the API's Pokemon index. Count on the
+// returned list is the total number of Pokemon available, not the page size.
+func (c *Client) ListPokemon(ctx context.Context, limit, offset int) (PokemonList, error) {
+	url := fmt.Sprintf("%s/pokemon/?limit=%d&offset=%d", c.BaseURL, limit, offset)
+	body, err := c.fetchData(ctx, url)
+	if err != nil {
+		return PokemonList{}, err
+	}
+
+	var list PokemonList
+	if err := json.Unmarshal(body, &list); err != nil {
+		return PokemonList{}, fmt.Errorf("error parsing JSON: %v", err)
+	}
+
+	return list, nil
+}
+
+// ListAllPokemon fetches the whole index: one request for the count, then one
+// for every entry.
+func (c *Client) ListAllPokemon(ctx context.Context) ([]PokemonListEntry, error) {
+	first, err := c.ListPokemon(ctx, 1, 0)
+	if err != nil {
+		return nil, err
+	}
+	if first.Count <= len(first.Results) {
+		return first.Results, nil
+	}
+
+	list, err := c.ListPokemon(ctx, first.Count, 0)
+	if err != nil {
+		return nil, err
+	}
+	return list.Results, nil
+}
+
+// Ping checks that the API answers by fetching the smallest possible page of
+// the index, returning how long that took.
+func (c *Client) Ping(ctx context.Context) (time.Duration, error) {
+	start := time.Now()
+	if _, err := c.ListPokemon(ctx, 1, 0); err != nil {
+		return 0, err
+	}
+	return time.Since(start), nil
+}
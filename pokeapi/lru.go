@@ -0,0 +1,66 @@
+package pokeapi
+
+import (
+	"container/list"
+	"sync"
+)
+
+// lruCache holds up to size parsed Pokemon, evicting the least recently used
+// entry when full. It is safe for concurrent use.
+type lruCache struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List
+	items map[string]*list.Element
+}
+
+type lruEntry struct {
+	key     string
+	pokemon Pokemon
+}
+
+func newLRUCache(size int) *lruCache {
+	return &lruCache{
+		size:  size,
+		order: list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+func (c *lruCache) Get(key string) (Pokemon, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return Pokemon{}, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*lruEntry).pokemon, true
+}
+
+func (c *lruCache) Add(key string, pokemon Pokemon) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		elem.Value.(*lruEntry).pokemon = pokemon
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&lruEntry{key, pokemon})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry).key)
+	}
+}
+
+func (c *lruCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	c.items = make(map[string]*list.Element)
+}
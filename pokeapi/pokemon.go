@@ -0,0 +1,286 @@
+package pokeapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type Stat struct {
+	Name string `json:"name" yaml:"name"`
+	URL  string `json:"url" yaml:"url"`
+}
+
+type StatInfo struct {
+	Stat     Stat  `json:"stat" yaml:"stat"`
+	BaseStat int32 `json:"base_stat" yaml:"base_stat"`
+}
+
+type Type struct {
+	Name string `json:"name" yaml:"name"`
+	URL  string `json:"url" yaml:"url"`
+}
+
+type TypeInfo struct {
+	Slot int32 `json:"slot" yaml:"slot"`
+	Type Type  `json:"type" yaml:"type"`
+}
+
+type Ability struct {
+	Name string `json:"name" yaml:"name"`
+	URL  string `json:"url" yaml:"url"`
+}
+
+type AbilityInfo struct {
+	Ability  Ability `json:"ability" yaml:"ability"`
+	IsHidden bool    `json:"is_hidden" yaml:"is_hidden"`
+	Slot     int32   `json:"slot" yaml:"slot"`
+}
+
+type MoveEntry struct {
+	Name string `json:"name" yaml:"name"`
+	URL  string `json:"url" yaml:"url"`
+}
+
+type MoveInfo struct {
+	Move MoveEntry `json:"move" yaml:"move"`
+}
+
+type HeldItem struct {
+	Name string `json:"name" yaml:"name"`
+	URL  string `json:"url" yaml:"url"`
+}
+
+type HeldItemInfo struct {
+	Item HeldItem `json:"item" yaml:"item"`
+}
+
+// Form is one of a Pokemon's forms. Every Pokemon has at least its default
+// form; alternate ones such as Alolan or Mega forms can be fetched by name.
+type Form struct {
+	Name string `json:"name" yaml:"name"`
+	URL  string `json:"url" yaml:"url"`
+}
+
+// Cries holds the URLs of a Pokemon's cry recordings in Ogg Vorbis. Legacy
+// is the original game sound and is empty for newer Pokemon.
+type Cries struct {
+	Latest string `json:"latest" yaml:"latest"`
+	Legacy string `json:"legacy" yaml:"legacy"`
+}
+
+type OfficialArtwork struct {
+	FrontDefault string `json:"front_default" yaml:"front_default"`
+}
+
+type OtherSprites struct {
+	OfficialArtwork OfficialArtwork `json:"official-artwork" yaml:"official-artwork"`
+}
+
+type Sprites struct {
+	FrontDefault string       `json:"front_default" yaml:"front_default"`
+	BackDefault  string       `json:"back_default" yaml:"back_default"`
+	FrontShiny   string       `json:"front_shiny" yaml:"front_shiny"`
+	BackShiny    string       `json:"back_shiny" yaml:"back_shiny"`
+	Other        OtherSprites `json:"other" yaml:"other"`
+
+	// All maps the slash-separated JSON path of every non-null sprite URL in
+	// the response, including game versions the fields above don't cover,
+	// to that URL.
+	All map[string]string `json:"-" yaml:"-"`
+}
+
+// String formats the named sprite fields only, leaving out All, which for a
+// real API response holds dozens of per-game URLs.
+func (s Sprites) String() string {
+	type named struct {
+		FrontDefault, BackDefault, FrontShiny, BackShiny string
+		Other                                            OtherSprites
+	}
+	return fmt.Sprint(named{s.FrontDefault, s.BackDefault, s.FrontShiny, s.BackShiny, s.Other})
+}
+
+func (s *Sprites) UnmarshalJSON(data []byte) error {
+	type plain Sprites
+	if err := json.Unmarshal(data, (*plain)(s)); err != nil {
+		return err
+	}
+
+	var tree map[string]interface{}
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return err
+	}
+	s.All = make(map[string]string)
+	collectSpriteURLs("", tree, s.All)
+	return nil
+}
+
+// URLs returns every non-empty sprite URL keyed by its slash-separated JSON
+// path, such as "front_default" or "other/official-artwork/front_default".
+// It includes the named fields even for a Sprites that wasn't decoded from
+// JSON and so has no All map.
+func (s Sprites) URLs() map[string]string {
+	urls := make(map[string]string, len(s.All)+5)
+	for path, url := range s.All {
+		urls[path] = url
+	}
+
+	named := map[string]string{
+		"front_default":                        s.FrontDefault,
+		"back_default":                         s.BackDefault,
+		"front_shiny":                          s.FrontShiny,
+		"back_shiny":                           s.BackShiny,
+		"other/official-artwork/front_default": s.Other.OfficialArtwork.FrontDefault,
+	}
+	for path, url := range named {
+		if url != "" {
+			urls[path] = url
+		}
+	}
+	return urls
+}
+
+func collectSpriteURLs(prefix string, tree map[string]interface{}, urls map[string]string) {
+	for key, value := range tree {
+		keyPath := key
+		if prefix != "" {
+			keyPath = prefix + "/" + key
+		}
+
+		switch v := value.(type) {
+		case string:
+			if v != "" {
+				urls[keyPath] = v
+			}
+		case map[string]interface{}:
+			collectSpriteURLs(keyPath, v, urls)
+		}
+	}
+}
+
+type Pokemon struct {
+	Name      string         `json:"name" yaml:"name"`
+	BaseExp   int32          `json:"base_experience" yaml:"base_experience"`
+	Height    int32          `json:"height" yaml:"height"`
+	Weight    int32          `json:"weight" yaml:"weight"`
+	Id        int32          `json:"id" yaml:"id"`
+	Sprites   Sprites        `json:"sprites" yaml:"sprites"`
+	StatInfo  []StatInfo     `json:"stats" yaml:"stats"`
+	Types     []TypeInfo     `json:"types" yaml:"types"`
+	Abilities []AbilityInfo  `json:"abilities" yaml:"abilities"`
+	Moves     []MoveInfo     `json:"moves" yaml:"moves"`
+	Items     []HeldItemInfo `json:"held_items" yaml:"held_items"`
+	Forms     []Form         `json:"forms" yaml:"forms"`
+	Species   Species        `json:"species" yaml:"species"`
+	Cries     Cries          `json:"cries" yaml:"cries"`
+}
+
+// WeightKg converts Weight, which PokeAPI reports in hectograms, to kilograms.
+func (p Pokemon) WeightKg() float64 {
+	return float64(p.Weight) / 10
+}
+
+// HeightM converts Height, which PokeAPI reports in decimetres, to metres.
+func (p Pokemon) HeightM() float64 {
+	return float64(p.Height) / 10
+}
+
+// TotalStats returns the sum of all base stats, the "base stat total".
+func (p Pokemon) TotalStats() int32 {
+	var total int32
+	for _, info := range p.StatInfo {
+		total += info.BaseStat
+	}
+	return total
+}
+
+// AbilityNames lists the Pokemon's abilities, marking hidden ones.
+func (p Pokemon) AbilityNames() []string {
+	abilities := make([]string, len(p.Abilities))
+	for i, a := range p.Abilities {
+		abilities[i] = a.Ability.Name
+		if a.IsHidden {
+			abilities[i] += " (hidden)"
+		}
+	}
+	return abilities
+}
+
+// MoveNames returns the names of the moves the Pokemon can learn, in the
+// order the API lists them.
+func (p Pokemon) MoveNames() []string {
+	names := make([]string, len(p.Moves))
+	for i, m := range p.Moves {
+		names[i] = m.Move.Name
+	}
+	return names
+}
+
+// ItemNames returns the names of the items the Pokemon may hold in the wild.
+func (p Pokemon) ItemNames() []string {
+	names := make([]string, len(p.Items))
+	for i, item := range p.Items {
+		names[i] = item.Item.Name
+	}
+	return names
+}
+
+// FormNames returns the names of the Pokemon's forms.
+func (p Pokemon) FormNames() []string {
+	names := make([]string, len(p.Forms))
+	for i, f := range p.Forms {
+		names[i] = f.Name
+	}
+	return names
+}
+
+// Stat returns the base stat with the given name, matched case-insensitively,
+// and whether the Pokemon has it.
+func (p Pokemon) Stat(name string) (int32, bool) {
+	for _, info := range p.StatInfo {
+		if strings.EqualFold(info.Stat.Name, name) {
+			return info.BaseStat, true
+		}
+	}
+	return 0, false
+}
+
+// TypeNames returns the Pokemon's type names ordered by slot.
+func (p Pokemon) TypeNames() []string {
+	types := make([]TypeInfo, len(p.Types))
+	copy(types, p.Types)
+	sort.Slice(types, func(i, j int) bool { return types[i].Slot < types[j].Slot })
+
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = t.Type.Name
+	}
+	return names
+}
+
+// Validate reports an error if p is missing data every real Pokemon has,
+// which points at a partial or garbage response that still decoded.
+func (p Pokemon) Validate() error {
+	switch {
+	case p.Name == "":
+		return errors.New("invalid Pokemon data: missing name")
+	case p.Id <= 0:
+		return fmt.Errorf("invalid Pokemon data for %s: id %d is not positive", p.Name, p.Id)
+	case len(p.StatInfo) == 0:
+		return fmt.Errorf("invalid Pokemon data for %s: no stats", p.Name)
+	}
+	return nil
+}
+
+// ParsePokemon decodes a PokeAPI /pokemon response body.
+func ParsePokemon(body []byte) (Pokemon, error) {
+	var data Pokemon
+	err := json.Unmarshal(body, &data)
+	if err != nil {
+		return Pokemon{}, fmt.Errorf("error parsing JSON: %v", err)
+	}
+
+	return data, nil
+}
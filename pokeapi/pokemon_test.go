@@ -0,0 +1,129 @@
+package pokeapi
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSpritesStringOmitsAll(t *testing.T) {
+	var s Sprites
+	data := `{
+		"front_default": "https://example.com/front.png",
+		"versions": {"generation-i": {"red-blue": {"front_default": "https://example.com/red-blue.png"}}}
+	}`
+	if err := json.Unmarshal([]byte(data), &s); err != nil {
+		t.Fatal(err)
+	}
+
+	got := s.String()
+	if !strings.Contains(got, "https://example.com/front.png") {
+		t.Errorf("String() = %q, want the front sprite", got)
+	}
+	if strings.Contains(got, "red-blue") {
+		t.Errorf("String() = %q, want per-game sprites left out", got)
+	}
+}
+
+// fixturePokemon is pikachuJSON decoded.
+func fixturePokemon(t *testing.T) Pokemon {
+	t.Helper()
+	p, err := ParsePokemon([]byte(pikachuJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestTotalStats(t *testing.T) {
+	if got := fixturePokemon(t).TotalStats(); got != 320 {
+		t.Errorf("TotalStats() = %d, want 320", got)
+	}
+	if got := (Pokemon{}).TotalStats(); got != 0 {
+		t.Errorf("TotalStats() with no stats = %d, want 0", got)
+	}
+}
+
+func TestUnitConversions(t *testing.T) {
+	p := fixturePokemon(t)
+	if got := p.WeightKg(); got != 6 {
+		t.Errorf("WeightKg() = %v, want 6", got)
+	}
+	if got := p.HeightM(); got != 0.4 {
+		t.Errorf("HeightM() = %v, want 0.4", got)
+	}
+}
+
+func TestStat(t *testing.T) {
+	p := fixturePokemon(t)
+	tests := []struct {
+		name string
+		want int32
+	}{
+		{"hp", 35},
+		{"attack", 55},
+		{"defense", 40},
+		{"special-attack", 50},
+		{"special-defense", 50},
+		{"speed", 90},
+		{"Speed", 90},
+		{"SPECIAL-ATTACK", 50},
+	}
+	for _, tt := range tests {
+		got, ok := p.Stat(tt.name)
+		if !ok || got != tt.want {
+			t.Errorf("Stat(%q) = %d, %v, want %d, true", tt.name, got, ok, tt.want)
+		}
+	}
+
+	if got, ok := p.Stat("accuracy"); ok {
+		t.Errorf("Stat(accuracy) = %d, true, want not found", got)
+	}
+}
+
+func TestSpritesURLs(t *testing.T) {
+	decoded := fixturePokemon(t).Sprites.URLs()
+	want := map[string]string{
+		"front_default":                        "https://example.com/sprites/25.png",
+		"back_default":                         "https://example.com/sprites/back/25.png",
+		"other/official-artwork/front_default": "https://example.com/artwork/25.png",
+	}
+	if len(decoded) != len(want) {
+		t.Errorf("URLs() of decoded sprites = %v, want %v", decoded, want)
+	}
+	for path, url := range want {
+		if decoded[path] != url {
+			t.Errorf("URLs()[%q] = %q, want %q", path, decoded[path], url)
+		}
+	}
+
+	built := Sprites{FrontDefault: "https://example.com/front.png", BackShiny: ""}.URLs()
+	if len(built) != 1 || built["front_default"] != "https://example.com/front.png" {
+		t.Errorf("URLs() of built sprites = %v, want only front_default", built)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := fixturePokemon(t).Validate(); err != nil {
+		t.Errorf("Validate() of a complete Pokemon = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*Pokemon)
+		want   string
+	}{
+		{"empty name", func(p *Pokemon) { p.Name = "" }, "missing name"},
+		{"zero id", func(p *Pokemon) { p.Id = 0 }, "id 0 is not positive"},
+		{"negative id", func(p *Pokemon) { p.Id = -1 }, "id -1 is not positive"},
+		{"no stats", func(p *Pokemon) { p.StatInfo = nil }, "no stats"},
+	}
+	for _, tt := range tests {
+		p := fixturePokemon(t)
+		tt.modify(&p)
+		err := p.Validate()
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: Validate() = %v, want an error containing %q", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package pokeapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PrintOptions selects how PrintPokemon renders a Pokemon. Format is "text"
+// (the default when empty), "json" or "yaml". Moves, Items and Forms add
+// those sections to the text output; MovesLimit caps the moves listed when
+// positive. Structured formats always include every field.
+type PrintOptions struct {
+	Format     string
+	Moves      bool
+	MovesLimit int
+	Items      bool
+	Forms      bool
+}
+
+// PrintPokemon writes p to w in the format and with the sections opts asks
+// for.
+func PrintPokemon(w io.Writer, p Pokemon, opts PrintOptions) error {
+	switch opts.Format {
+	case "", "text":
+		return printText(w, p, opts)
+	case "json":
+		data, err := json.MarshalIndent(p, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error encoding JSON: %v", err)
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case "yaml":
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(p); err != nil {
+			return fmt.Errorf("error encoding YAML: %v", err)
+		}
+		return enc.Close()
+	default:
+		return fmt.Errorf("unsupported format %q", opts.Format)
+	}
+}
+
+func printText(w io.Writer, p Pokemon, opts PrintOptions) error {
+	ew := &errWriter{w: w}
+	ew.println("Pokemon Name:", p.Name)
+	ew.println("Pokemon BaseExp:", p.BaseExp)
+	ew.printf("Pokemon Height: %.1f m\n", p.HeightM())
+	ew.printf("Pokemon Weight: %.1f kg\n", p.WeightKg())
+	ew.println("Pokemon Id:", p.Id)
+	ew.println("Pokemon Types:", strings.Join(p.TypeNames(), ", "))
+	ew.println("Pokemon Sprites:", p.Sprites)
+	ew.println("Pokemon Stats:", p.StatInfo)
+	ew.println("Base Stat Total:", p.TotalStats())
+	ew.println("Pokemon Abilities:", strings.Join(p.AbilityNames(), ", "))
+
+	if opts.Moves {
+		moves := p.MoveNames()
+		shown := moves
+		if opts.MovesLimit > 0 && len(moves) > opts.MovesLimit {
+			shown = moves[:opts.MovesLimit]
+		}
+		ew.println("Pokemon Moves:", listOrNone(shown))
+		if len(shown) < len(moves) {
+			ew.printf("  ... and %d more\n", len(moves)-len(shown))
+		}
+	}
+	if opts.Items {
+		ew.println("Pokemon Held Items:", listOrNone(p.ItemNames()))
+	}
+	if opts.Forms {
+		ew.println("Pokemon Forms:", listOrNone(p.FormNames()))
+	}
+
+	return ew.err
+}
+
+func listOrNone(names []string) string {
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ", ")
+}
+
+// errWriter remembers the first write error so a run of prints can be
+// checked once at the end.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) println(args ...interface{}) {
+	if ew.err == nil {
+		_, ew.err = fmt.Fprintln(ew.w, args...)
+	}
+}
+
+func (ew *errWriter) printf(format string, args ...interface{}) {
+	if ew.err == nil {
+		_, ew.err = fmt.Fprintf(ew.w, format, args...)
+	}
+}
@@ -0,0 +1,58 @@
+package pokeapi
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+var progressMu sync.Mutex
+
+// progressReader reports how much of a response body has been read on a
+// single, repeatedly overwritten terminal line. A total of -1 means the
+// length is unknown and only the byte count is shown.
+type progressReader struct {
+	w       io.Writer
+	r       io.Reader
+	label   string
+	total   int64
+	read    int64
+	lastPct int64
+}
+
+func newProgressReader(w io.Writer, r io.Reader, label string, total int64) *progressReader {
+	return &progressReader{w: w, r: r, label: label, total: total, lastPct: -1}
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.read += int64(n)
+		p.report()
+	}
+	return n, err
+}
+
+func (p *progressReader) report() {
+	progressMu.Lock()
+	defer progressMu.Unlock()
+
+	if p.total > 0 {
+		pct := p.read * 100 / p.total
+		if pct == p.lastPct {
+			return
+		}
+		p.lastPct = pct
+		fmt.Fprintf(p.w, "\r\x1b[K%s: %d%%", p.label, pct)
+		return
+	}
+	fmt.Fprintf(p.w, "\r\x1b[K%s: %d bytes", p.label, p.read)
+}
+
+// Done clears the progress line so later log output starts on a clean line.
+func (p *progressReader) Done() {
+	progressMu.Lock()
+	defer progressMu.Unlock()
+
+	fmt.Fprint(p.w, "\r\x1b[K")
+}
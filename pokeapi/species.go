@@ -0,0 +1,66 @@
+package pokeapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type Species struct {
+	Name string `json:"name" yaml:"name"`
+	URL  string `json:"url" yaml:"url"`
+}
+
+type Language struct {
+	Name string `json:"name" yaml:"name"`
+	URL  string `json:"url" yaml:"url"`
+}
+
+type FlavorTextEntry struct {
+	FlavorText string   `json:"flavor_text" yaml:"flavor_text"`
+	Language   Language `json:"language" yaml:"language"`
+}
+
+type SpeciesInfo struct {
+	Id                int32              `json:"id" yaml:"id"`
+	Name              string             `json:"name" yaml:"name"`
+	FlavorTextEntries []FlavorTextEntry  `json:"flavor_text_entries" yaml:"flavor_text_entries"`
+	EvolutionChain    EvolutionChainLink `json:"evolution_chain" yaml:"evolution_chain"`
+}
+
+// FlavorText returns the first Pokedex entry written in lang, with the line
+// breaks and form feeds from the original game text collapsed into spaces.
+func (s SpeciesInfo) FlavorText(lang string) (string, bool) {
+	for _, entry := range s.FlavorTextEntries {
+		if entry.Language.Name == lang {
+			return cleanFlavorText(entry.FlavorText), true
+		}
+	}
+	return "", false
+}
+
+func cleanFlavorText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// GetSpecies follows the Pokemon's species link, falling back to looking the
+// species up by ID when the link is missing.
+func (c *Client) GetSpecies(ctx context.Context, pokemon Pokemon) (SpeciesInfo, error) {
+	url := pokemon.Species.URL
+	if url == "" {
+		url = fmt.Sprintf("%s/pokemon-species/%d/", c.BaseURL, pokemon.Id)
+	}
+
+	body, err := c.fetchData(ctx, url)
+	if err != nil {
+		return SpeciesInfo{}, err
+	}
+
+	var species SpeciesInfo
+	if err := json.Unmarshal(body, &species); err != nil {
+		return SpeciesInfo{}, fmt.Errorf("error parsing species JSON: %v", err)
+	}
+
+	return species, nil
+}
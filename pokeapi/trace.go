@@ -0,0 +1,60 @@
+package pokeapi
+
+import (
+	"fmt"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// requestTiming records when each phase of a single HTTP request happened,
+// starting from when the transport first asks for a connection. Phases that
+// didn't occur, such as DNS on a reused connection, stay zero.
+type requestTiming struct {
+	mu           sync.Mutex
+	start        time.Time
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	gotConn      time.Time
+	firstByte    time.Time
+}
+
+func (t *requestTiming) mark(field *time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if field.IsZero() {
+		*field = time.Now()
+	}
+}
+
+func (t *requestTiming) trace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GetConn:              func(string) { t.mark(&t.start) },
+		DNSStart:             func(httptrace.DNSStartInfo) { t.mark(&t.dnsStart) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { t.mark(&t.dnsDone) },
+		ConnectStart:         func(string, string) { t.mark(&t.connectStart) },
+		GotConn:              func(httptrace.GotConnInfo) { t.mark(&t.gotConn) },
+		GotFirstResponseByte: func() { t.mark(&t.firstByte) },
+	}
+}
+
+func since(from, to time.Time) time.Duration {
+	if from.IsZero() || to.IsZero() {
+		return 0
+	}
+	return to.Sub(from)
+}
+
+// breakdown describes how long each phase took, given that the body was
+// fully read at end. Connect includes the TLS handshake.
+func (t *requestTiming) breakdown(end time.Time) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return fmt.Sprintf("dns %v, connect %v, first byte %v, body %v, total %v",
+		since(t.dnsStart, t.dnsDone),
+		since(t.connectStart, t.gotConn),
+		since(t.gotConn, t.firstByte),
+		since(t.firstByte, end),
+		since(t.start, end))
+}
@@ -0,0 +1,57 @@
+package pokeapi
+
+import (
+	"bytes"
+	"fmt"
+	"image/gif"
+	"image/png"
+	"path"
+	"strings"
+)
+
+var pngMagic = []byte("\x89PNG\r\n\x1a\n")
+
+// ValidateSprite checks that data looks like the image type its URL names.
+// Anything that isn't a GIF or SVG is expected to be a PNG. DownloadSprite
+// applies it to every sprite; it is exported for data fetched with
+// DownloadFile.
+func ValidateSprite(url string, data []byte) error {
+	switch strings.ToLower(path.Ext(url)) {
+	case ".gif":
+		if _, err := gif.DecodeConfig(bytes.NewReader(data)); err != nil {
+			return fmt.Errorf("invalid GIF image: %v", err)
+		}
+		return nil
+	case ".svg":
+		if !bytes.Contains(data, []byte("<svg")) {
+			return fmt.Errorf("response is not an SVG image (%d bytes, starts with %q)", len(data), preview(data))
+		}
+		return nil
+	default:
+		return validatePNG(data)
+	}
+}
+
+// validatePNG reports an error unless data is a complete, decodable PNG. It
+// guards against saving an HTML error page or a truncated body as a sprite.
+func validatePNG(data []byte) error {
+	if !bytes.HasPrefix(data, pngMagic) {
+		return fmt.Errorf("response is not a PNG image (%d bytes, starts with %q)", len(data), preview(data))
+	}
+	if _, err := png.Decode(bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("invalid PNG image: %v", err)
+	}
+	return nil
+}
+
+func preview(data []byte) []byte {
+	if len(data) > 16 {
+		return data[:16]
+	}
+	return data
+}
+
+// IsPNG reports whether data starts with the PNG file signature.
+func IsPNG(data []byte) bool {
+	return bytes.HasPrefix(data, pngMagic)
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"example/start/pokeapi"
+)
+
+const defaultConcurrency = 4
+
+// downloadJob is one file to save. Jobs with raw set, such as cries, aren't
+// images: they skip sprite validation and conversion and are saved as is.
+type downloadJob struct {
+	pokemon  string
+	label    string
+	url      string
+	filename string
+	raw      bool
+}
+
+func (j downloadJob) String() string {
+	if j.raw {
+		return j.pokemon + " " + j.label
+	}
+	return j.pokemon + " " + j.label + " sprite"
+}
+
+// downloadResult carries the job's index in the slice given to Run, since
+// results arrive in the order downloads finish. reused is set when the data
+// came from another job's download of the same URL.
+type downloadResult struct {
+	index  int
+	job    downloadJob
+	data   []byte
+	err    error
+	reused bool
+}
+
+// downloadPool runs downloads on a fixed number of workers so a large
+// batch never has more than that many requests in flight at once; sprites
+// started early with prefetch count towards the same limit. A URL that
+// several jobs share, such as a sprite used by two forms, is only downloaded
+// once per run, and dropped from memory once the last of them has it. With
+// keep set, every downloaded file stays in memory so a later Run reuses it
+// rather than fetching it again. With lenient set,
+// sprites aren't validated as they download, leaving the caller to decide
+// what to do with bad ones.
+type downloadPool struct {
+	client      *pokeapi.Client
+	concurrency int
+	timeout     time.Duration
+	keep        bool
+	lenient     bool
+
+	// shared maps each URL that more than one job of the current Run needs
+	// to the index of the first of them; pending counts the jobs of the
+	// current Run still to take each URL's data.
+	mu        sync.Mutex
+	shared    map[string]int
+	pending   map[string]int
+	downloads map[string]*download
+
+	// slots holds a token for every download in flight.
+	slots chan struct{}
+}
+
+// download is one URL's download, shared by every job that needs it. done is
+// closed once data and err are set.
+type download struct {
+	done chan struct{}
+	data []byte
+	err  error
+}
+
+func newDownloadPool(client *pokeapi.Client, concurrency int, timeout time.Duration) *downloadPool {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	return &downloadPool{
+		client:      client,
+		concurrency: concurrency,
+		timeout:     timeout,
+		shared:      make(map[string]int),
+		pending:     make(map[string]int),
+		downloads:   make(map[string]*download),
+		slots:       make(chan struct{}, concurrency),
+	}
+}
+
+// fetch downloads job's URL once a slot is free.
+func (p *downloadPool) fetch(ctx context.Context, job downloadJob) ([]byte, error) {
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-p.slots }()
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	if job.raw || p.lenient {
+		return p.client.DownloadFile(ctx, job.url)
+	}
+	return p.client.DownloadSprite(ctx, job.url)
+}
+
+// finish records the outcome of d, forgetting it again on failure so a later
+// Run tries the URL afresh.
+func (p *downloadPool) finish(url string, d *download, data []byte, err error) {
+	d.data, d.err = data, err
+	if err != nil {
+		p.mu.Lock()
+		delete(p.downloads, url)
+		p.mu.Unlock()
+	}
+	close(d.done)
+}
+
+// release notes that one more job has taken d's data, forgetting d once no
+// job of the current Run still needs it, unless keep is set.
+func (p *downloadPool) release(url string, d *download) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pending[url]--
+	if p.pending[url] > 0 {
+		return
+	}
+	delete(p.pending, url)
+	if !p.keep && p.downloads[url] == d {
+		delete(p.downloads, url)
+	}
+}
+
+// prefetch starts downloading job's URL in the background, unless it is
+// already downloading or downloaded, so a later Run finds the data waiting.
+// That lets sprite downloads overlap with fetching the rest of a batch.
+func (p *downloadPool) prefetch(ctx context.Context, job downloadJob) {
+	p.mu.Lock()
+	if _, ok := p.downloads[job.url]; ok {
+		p.mu.Unlock()
+		return
+	}
+	d := &download{done: make(chan struct{})}
+	p.downloads[job.url] = d
+	p.mu.Unlock()
+
+	go func() {
+		data, err := p.fetch(ctx, job)
+		p.finish(job.url, d, data, err)
+	}()
+}
+
+// get downloads the URL of the job at index i, or waits for and reuses
+// another job's download of it. It reports the data as reused for every job
+// sharing a URL but the first, whichever of them actually downloaded it, so
+// the log reads the same on every run.
+func (p *downloadPool) get(ctx context.Context, i int, job downloadJob) ([]byte, bool, error) {
+	url := job.url
+	p.mu.Lock()
+	first, shared := p.shared[url]
+	reused := shared && i != first
+	if d, ok := p.downloads[url]; ok {
+		p.mu.Unlock()
+		select {
+		case <-d.done:
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		}
+		p.release(url, d)
+		return d.data, reused && d.err == nil, d.err
+	}
+
+	var d *download
+	if p.keep || shared {
+		d = &download{done: make(chan struct{})}
+		p.downloads[url] = d
+	}
+	p.mu.Unlock()
+
+	data, err := p.fetch(ctx, job)
+	if d != nil {
+		p.finish(url, d, data, err)
+		p.release(url, d)
+	}
+	return data, reused && err == nil, err
+}
+
+func (p *downloadPool) worker(ctx context.Context, wg *sync.WaitGroup, jobs []downloadJob, indexes <-chan int, results chan<- downloadResult) {
+	defer wg.Done()
+
+	for i := range indexes {
+		data, reused, err := p.get(ctx, i, jobs[i])
+		results <- downloadResult{i, jobs[i], data, err, reused}
+	}
+}
+
+// Run feeds jobs to the workers and returns the channel results are delivered
+// on. The channel is closed once every job has finished. Cancelling ctx aborts
+// any downloads still in flight.
+func (p *downloadPool) Run(ctx context.Context, jobs []downloadJob) <-chan downloadResult {
+	first := make(map[string]int, len(jobs))
+	p.mu.Lock()
+	p.shared = make(map[string]int)
+	p.pending = make(map[string]int)
+	for i, job := range jobs {
+		p.pending[job.url]++
+		if f, ok := first[job.url]; ok {
+			p.shared[job.url] = f
+			continue
+		}
+		first[job.url] = i
+	}
+	p.mu.Unlock()
+
+	indexes := make(chan int)
+	results := make(chan downloadResult)
+
+	var wg sync.WaitGroup
+	for i := 0; i < p.concurrency; i++ {
+		wg.Add(1)
+		go p.worker(ctx, &wg, jobs, indexes, results)
+	}
+
+	go func() {
+		for i := range jobs {
+			indexes <- i
+		}
+		close(indexes)
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
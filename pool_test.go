@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"example/start/pokeapi"
+)
+
+// benchLatency is how long the benchmark server takes to answer any request.
+const benchLatency = 10 * time.Millisecond
+
+// newBenchServer serves a minimal Pokemon for every /pokemon/<name>/ path,
+// with front and back sprites, answering each request after benchLatency.
+func newBenchServer(tb testing.TB) *httptest.Server {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 1, 1))); err != nil {
+		tb.Fatal(err)
+	}
+	sprite := buf.Bytes()
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(benchLatency)
+		if strings.HasPrefix(r.URL.Path, "/sprites/") {
+			w.Write(sprite)
+			return
+		}
+		name := strings.Trim(strings.TrimPrefix(r.URL.Path, "/pokemon/"), "/")
+		fmt.Fprintf(w, `{"name": %q, "id": %d, "stats": [{"base_stat": 1, "stat": {"name": "hp"}}],
+			"sprites": {"front_default": "%s/sprites/%s/front.png", "back_default": "%s/sprites/%s/back.png"}}`,
+			name, len(name), srv.URL, name, srv.URL, name)
+	}))
+	return srv
+}
+
+func benchNames(n int) []string {
+	names := make([]string, n)
+	for i := range names {
+		names[i] = strings.Repeat("a", i+1)
+	}
+	return names
+}
+
+// benchmarkDownloads fetches a batch and downloads its sprites, starting each
+// Pokemon's downloads as soon as it is fetched when pipelined is set, or
+// only once the whole batch has been fetched otherwise.
+func benchmarkDownloads(b *testing.B, pipelined bool) {
+	srv := newBenchServer(b)
+	defer srv.Close()
+	names := benchNames(16)
+	opts := spriteOptions{format: "png"}
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		client := pokeapi.NewClient(srv.URL)
+		pool := newDownloadPool(client, defaultConcurrency, time.Minute)
+
+		var jobs []downloadJob
+		for result := range fetchOrdered(ctx, client, names, time.Minute, false) {
+			if result.err != nil {
+				b.Fatal(result.err)
+			}
+			newJobs := spriteJobs(result.pokemon, ".", opts)
+			if pipelined {
+				for _, job := range newJobs {
+					pool.prefetch(ctx, job)
+				}
+			}
+			jobs = append(jobs, newJobs...)
+		}
+
+		for result := range pool.Run(ctx, jobs) {
+			if result.err != nil {
+				b.Fatal(result.err)
+			}
+		}
+	}
+}
+
+func BenchmarkFetchThenDownload(b *testing.B) { benchmarkDownloads(b, false) }
+
+func BenchmarkPipelinedDownload(b *testing.B) { benchmarkDownloads(b, true) }
+
+func TestPoolForgetsDeliveredDownloads(t *testing.T) {
+	srv := newBenchServer(t)
+	defer srv.Close()
+	ctx := context.Background()
+
+	front := downloadJob{pokemon: "a", label: "front", url: srv.URL + "/sprites/a/front.png"}
+	back := downloadJob{pokemon: "a", label: "back", url: srv.URL + "/sprites/a/back.png"}
+	jobs := []downloadJob{front, back, front}
+
+	for _, keep := range []bool{false, true} {
+		pool := newDownloadPool(pokeapi.NewClient(srv.URL), defaultConcurrency, time.Minute)
+		pool.keep = keep
+		pool.prefetch(ctx, front)
+		for result := range pool.Run(ctx, jobs) {
+			if result.err != nil {
+				t.Fatal(result.err)
+			}
+		}
+
+		want := 0
+		if keep {
+			want = 2
+		}
+		if got := len(pool.downloads); got != want {
+			t.Errorf("keep=%v: %d downloads held after Run, want %d", keep, got, want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"example/start/pokeapi"
+)
+
+// exitAmbiguous is the exit status when a -prefix name matches more than one
+// Pokemon, so scripts can tell it apart from a failed fetch.
+const exitAmbiguous = 3
+
+// matchPrefix returns the names in entries that start with prefix. An exact
+// match is returned on its own, so "mew" doesn't also match "mewtwo".
+func matchPrefix(entries []pokeapi.PokemonListEntry, prefix string) []string {
+	var matches []string
+	for _, entry := range entries {
+		if entry.Name == prefix {
+			return []string{entry.Name}
+		}
+		if strings.HasPrefix(entry.Name, prefix) {
+			matches = append(matches, entry.Name)
+		}
+	}
+	return matches
+}
+
+// resolvePrefixes replaces every name with the one Pokemon whose name starts
+// with it, for -prefix. IDs are passed through unchanged. When a prefix
+// matches nothing or several Pokemon, the candidates are printed and the
+// returned exit status is non-zero.
+func resolvePrefixes(ctx context.Context, client *pokeapi.Client, names []string, timeout time.Duration) ([]string, int) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	entries, err := client.ListAllPokemon(ctx)
+	if err != nil {
+		logError("Error listing Pokemon: %v", err)
+		return nil, 1
+	}
+
+	resolved := make([]string, 0, len(names))
+	status := 0
+	for _, name := range names {
+		if _, err := strconv.Atoi(name); err == nil {
+			resolved = append(resolved, name)
+			continue
+		}
+
+		matches := matchPrefix(entries, name)
+		switch len(matches) {
+		case 0:
+			logError("No Pokemon name starts with %q", name)
+			if status == 0 {
+				status = 1
+			}
+		case 1:
+			if matches[0] != name {
+				logInfo("Using %s for %s", matches[0], name)
+			}
+			resolved = append(resolved, matches[0])
+		default:
+			fmt.Fprintf(os.Stderr, "%q matches %d Pokemon:\n", name, len(matches))
+			for _, match := range matches {
+				fmt.Fprintf(os.Stderr, "  %s\n", match)
+			}
+			status = exitAmbiguous
+		}
+	}
+	return resolved, status
+}
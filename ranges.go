@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"example/start/pokeapi"
+)
+
+// idRanges is a flag.Value collecting Pokedex IDs from ranges such as 1-151
+// and single IDs, given comma-separated or by repeating the flag.
+type idRanges struct {
+	specs []string
+	ids   []string
+}
+
+func (r *idRanges) String() string {
+	if r == nil {
+		return ""
+	}
+	return strings.Join(r.specs, ",")
+}
+
+func (r *idRanges) Set(value string) error {
+	for _, spec := range strings.Split(value, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		start, end, err := parseRange(spec)
+		if err != nil {
+			return err
+		}
+		for id := start; id <= end; id++ {
+			r.ids = append(r.ids, strconv.Itoa(id))
+		}
+		r.specs = append(r.specs, spec)
+	}
+	return nil
+}
+
+// parseRange parses "start-end" or a single ID, which is a range of one.
+func parseRange(spec string) (int, int, error) {
+	startText, endText := spec, spec
+	if i := strings.Index(spec, "-"); i >= 0 {
+		startText, endText = spec[:i], spec[i+1:]
+	}
+
+	start, err := parseRangeID(startText)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid range %q: %v", spec, err)
+	}
+	end, err := parseRangeID(endText)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid range %q: %v", spec, err)
+	}
+	if start > end {
+		return 0, 0, fmt.Errorf("invalid range %q: start is after end", spec)
+	}
+	return start, end, nil
+}
+
+func parseRangeID(text string) (int, error) {
+	text = strings.TrimSpace(text)
+	id, err := strconv.Atoi(text)
+	if err != nil || strings.HasPrefix(text, "+") {
+		return 0, fmt.Errorf("%q is not a Pokedex ID", text)
+	}
+	if id < 1 || id > pokeapi.MaxPokemonID {
+		return 0, fmt.Errorf("%d is not between 1 and %d", id, pokeapi.MaxPokemonID)
+	}
+	return id, nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"strings"
+
+	"example/start/pokeapi"
+)
+
+// stringList is a flag.Value that collects every use of a repeatable flag,
+// also splitting each value on commas.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	for _, s := range strings.Split(value, ",") {
+		if s = strings.ToLower(strings.TrimSpace(s)); s != "" {
+			*l = append(*l, s)
+		}
+	}
+	return nil
+}
+
+// filterStats keeps only the stats named in wanted, in the order the API
+// returned them, and reports any wanted names the Pokemon doesn't have.
+func filterStats(stats []pokeapi.StatInfo, wanted []string) ([]pokeapi.StatInfo, []string) {
+	found := make(map[string]bool)
+	var kept []pokeapi.StatInfo
+	for _, s := range stats {
+		for _, name := range wanted {
+			if s.Stat.Name == name {
+				kept = append(kept, s)
+				found[name] = true
+				break
+			}
+		}
+	}
+
+	var missing []string
+	for _, name := range wanted {
+		if !found[name] {
+			missing = append(missing, name)
+		}
+	}
+	return kept, missing
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"example/start/pokeapi"
+)
+
+// printSummary renders the lowest, mean and highest value of each stat across
+// a batch, followed by the Pokemon with the highest base stat total. A stat
+// only counts towards the Pokemon that have it.
+func printSummary(w io.Writer, pokemon []pokeapi.Pokemon) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Stat\tMin\tMean\tMax\n")
+
+	row := func(label string, values []int32) {
+		if len(values) == 0 {
+			return
+		}
+		lowest, highest := values[0], values[0]
+		var total int64
+		for _, v := range values {
+			total += int64(v)
+			if v < lowest {
+				lowest = v
+			}
+			if v > highest {
+				highest = v
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%.1f\t%d\n", label, lowest, float64(total)/float64(len(values)), highest)
+	}
+
+	for _, name := range statColumns(pokemon) {
+		var values []int32
+		for _, p := range pokemon {
+			if v, ok := p.Stat(name); ok {
+				values = append(values, v)
+			}
+		}
+		row(name, values)
+	}
+
+	best := pokemon[0]
+	totals := make([]int32, len(pokemon))
+	for i, p := range pokemon {
+		totals[i] = p.TotalStats()
+		if totals[i] > best.TotalStats() {
+			best = p
+		}
+	}
+	row("total", totals)
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "Highest base stat total: %s (%d)\n", best.Name, best.TotalStats())
+	return err
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"example/start/pokeapi"
+)
+
+// printTable renders a Pokemon's scalar fields and stats as an aligned
+// two-column table.
+func printTable(w io.Writer, pokemon pokeapi.Pokemon) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	row := func(label string, value interface{}) {
+		fmt.Fprintf(tw, "%s\t%v\n", label, value)
+	}
+
+	row("Name", pokemon.Name)
+	row("Id", pokemon.Id)
+	row("Base experience", pokemon.BaseExp)
+	row("Height", fmt.Sprintf("%.1f m", pokemon.HeightM()))
+	row("Weight", fmt.Sprintf("%.1f kg", pokemon.WeightKg()))
+	row("Types", strings.Join(pokemon.TypeNames(), ", "))
+	row("Abilities", strings.Join(pokemon.AbilityNames(), ", "))
+	for _, info := range pokemon.StatInfo {
+		row(info.Stat.Name, info.BaseStat)
+	}
+	row("Base stat total", pokemon.TotalStats())
+
+	return tw.Flush()
+}
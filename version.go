@@ -0,0 +1,19 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// Build information, set at build time with e.g.
+//
+//	go build -ldflags "-X main.version=1.2.0 -X main.commit=$(git rev-parse --short HEAD) -X main.date=$(date -u +%Y-%m-%d)"
+var (
+	version = "dev"
+	commit  = "unknown"
+	date    = "unknown"
+)
+
+func printVersion(w io.Writer) {
+	fmt.Fprintf(w, "gopoke %s (commit %s, built %s)\n", version, commit, date)
+}